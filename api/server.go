@@ -2,8 +2,11 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"github.com/kubemq-hub/kubemq-targets/binding"
+	"github.com/kubemq-hub/kubemq-targets/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"time"
@@ -40,18 +43,53 @@ func Start(ctx context.Context, port int, bs *binding.Service) (*Server, error)
 	s.echoWebServer.GET("/bindings/stats", func(c echo.Context) error {
 		return c.JSONPretty(200, s.bindingService.Stats(), "\t")
 	})
+	s.echoWebServer.GET("/bindings/reload", func(c echo.Context) error {
+		return c.JSONPretty(200, s.bindingService.LastReload(), "\t")
+	})
 	s.echoWebServer.POST("/bindings/request", func(c echo.Context) error {
 		req := &binding.Request{}
 		err := c.Bind(req)
 		if err != nil {
-			return c.JSONPretty(400, struct {
-				Error string
-			}{
-				Error: fmt.Sprintf("invalid request, %s", err.Error()),
-			}, "\t")
+			return c.JSONPretty(400, errorResponse(fmt.Errorf("invalid request, %w", err)), "\t")
 		}
 		return c.JSONPretty(200, s.bindingService.SendRequest(c.Request().Context(), req), "\t")
 	})
+	s.echoWebServer.GET("/bindings/:name", func(c echo.Context) error {
+		status, err := s.bindingService.GetBindingStatus(c.Param("name"))
+		if err != nil {
+			return c.JSONPretty(errorCode(err), errorResponse(err), "\t")
+		}
+		return c.JSONPretty(200, status, "\t")
+	})
+	s.echoWebServer.POST("/bindings", func(c echo.Context) error {
+		cfg := config.BindingConfig{}
+		err := c.Bind(&cfg)
+		if err != nil {
+			return c.JSONPretty(400, errorResponse(fmt.Errorf("invalid binding config, %w", err)), "\t")
+		}
+		return s.bindingResult(c, cfg.Name, s.bindingService.CreateBinding(cfg))
+	}, s.authorize)
+	s.echoWebServer.PUT("/bindings/:name", func(c echo.Context) error {
+		cfg := config.BindingConfig{}
+		err := c.Bind(&cfg)
+		if err != nil {
+			return c.JSONPretty(400, errorResponse(fmt.Errorf("invalid binding config, %w", err)), "\t")
+		}
+		return s.bindingResult(c, c.Param("name"), s.bindingService.UpdateBinding(c.Param("name"), cfg))
+	}, s.authorize)
+	s.echoWebServer.DELETE("/bindings/:name", func(c echo.Context) error {
+		err := s.bindingService.DeleteBinding(c.Param("name"))
+		if err != nil {
+			return c.JSONPretty(errorCode(err), errorResponse(err), "\t")
+		}
+		return c.NoContent(204)
+	}, s.authorize)
+	s.echoWebServer.POST("/bindings/:name/pause", func(c echo.Context) error {
+		return s.bindingResult(c, c.Param("name"), s.bindingService.PauseBinding(c.Param("name")))
+	}, s.authorize)
+	s.echoWebServer.POST("/bindings/:name/resume", func(c echo.Context) error {
+		return s.bindingResult(c, c.Param("name"), s.bindingService.ResumeBinding(c.Param("name")))
+	}, s.authorize)
 	errCh := make(chan error, 1)
 	go func() {
 		errCh <- s.echoWebServer.Start(fmt.Sprintf("0.0.0.0:%d", port))
@@ -75,3 +113,52 @@ func (s *Server) Stop() error {
 	defer cancel()
 	return s.echoWebServer.Shutdown(ctx)
 }
+
+type ErrorResponse struct {
+	Error string
+}
+
+func errorResponse(err error) *ErrorResponse {
+	return &ErrorResponse{
+		Error: err.Error(),
+	}
+}
+
+func errorCode(err error) int {
+	switch {
+	case errors.Is(err, binding.ErrBindingNotFound):
+		return 404
+	case errors.Is(err, binding.ErrBindingExists):
+		return 409
+	case errors.Is(err, binding.ErrInvalidBinding):
+		return 400
+	default:
+		return 500
+	}
+}
+
+func (s *Server) bindingResult(c echo.Context, name string, err error) error {
+	if err != nil {
+		return c.JSONPretty(errorCode(err), errorResponse(err), "\t")
+	}
+	status, err := s.bindingService.GetBindingStatus(name)
+	if err != nil {
+		return c.JSONPretty(errorCode(err), errorResponse(err), "\t")
+	}
+	return c.JSONPretty(200, status, "\t")
+}
+
+// authorize allows binding management requests only with a bearer token matching the api auth token
+func (s *Server) authorize(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := s.bindingService.ApiAuthToken()
+		if token == "" {
+			return c.JSONPretty(403, errorResponse(fmt.Errorf("bindings management is disabled, no api auth token was set")), "\t")
+		}
+		auth := c.Request().Header.Get("Authorization")
+		if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+token)) != 1 {
+			return c.JSONPretty(401, errorResponse(fmt.Errorf("unauthorized")), "\t")
+		}
+		return next(c)
+	}
+}
@@ -7,22 +7,32 @@ import (
 	"github.com/kubemq-hub/kubemq-targets/middleware"
 	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
 	"github.com/kubemq-hub/kubemq-targets/pkg/metrics"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"github.com/kubemq-hub/kubemq-targets/sources"
 	"github.com/kubemq-hub/kubemq-targets/targets"
+	"math"
+	"time"
 )
 
+const defaultDrainTimeoutMilliseconds = 30000
+
 type Binder struct {
-	name   string
-	log    *logger.Logger
-	source sources.Source
-	target targets.Target
-	md     middleware.Middleware
+	name         string
+	log          *logger.Logger
+	source       sources.Source
+	target       targets.Target
+	md           middleware.Middleware
+	dl           *middleware.DeadLetterMiddleware
+	cb           *middleware.CircuitBreakerMiddleware
+	recorder     *middleware.RecorderMiddleware
+	dedupTarget  targets.Target
+	drainTimeout time.Duration
 }
 
 func NewBinder() *Binder {
 	return &Binder{}
 }
-func (b *Binder) buildMiddleware(cfg config.BindingConfig, exporter *metrics.Exporter, log *middleware.LogMiddleware) (middleware.Middleware, error) {
+func (b *Binder) buildMiddleware(ctx context.Context, cfg config.BindingConfig, exporter *metrics.Exporter, log *middleware.LogMiddleware) (middleware.Middleware, error) {
 
 	retry, err := middleware.NewRetryMiddleware(cfg.Properties, b.log)
 	if err != nil {
@@ -36,23 +46,125 @@ func (b *Binder) buildMiddleware(cfg config.BindingConfig, exporter *metrics.Exp
 	if err != nil {
 		return nil, err
 	}
-	md := middleware.Chain(b.target, middleware.RateLimiter(rateLimiter), middleware.Retry(retry), middleware.Metric(met), middleware.Log(log))
+	timeout, err := middleware.NewTimeoutMiddleware(cfg.Properties)
+	if err != nil {
+		return nil, err
+	}
+	transform, err := middleware.NewTransformMiddleware(cfg.Properties)
+	if err != nil {
+		return nil, err
+	}
+	b.cb, err = middleware.NewCircuitBreakerMiddleware(cfg, exporter)
+	if err != nil {
+		return nil, err
+	}
+	b.dl, err = middleware.NewDeadLetterMiddleware(ctx, cfg, b.log)
+	if err != nil {
+		return nil, err
+	}
+	dedupStore, err := b.newDedupStore(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	dedup, err := middleware.NewDedupMiddleware(cfg.Properties, dedupStore)
+	if err != nil {
+		return nil, err
+	}
+	validation, err := middleware.NewValidationMiddleware(cfg.Properties, b.target.Connector())
+	if err != nil {
+		return nil, err
+	}
+	cache, err := middleware.NewCacheMiddleware(cfg.Properties)
+	if err != nil {
+		return nil, err
+	}
+	b.recorder, err = middleware.NewRecorderMiddleware(cfg.Name, cfg.Properties, b.log)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := middleware.NewBatchMiddleware(ctx, cfg.Properties, b.target)
+	if err != nil {
+		return nil, err
+	}
+	md := middleware.Chain(b.target,
+		middleware.TraceTarget(cfg.Target.Kind),
+		middleware.Trace("batch", middleware.Batch(batch)),
+		middleware.Trace("recorder", middleware.Recorder(b.recorder)),
+		middleware.Trace("timeout", middleware.Timeout(timeout)),
+		middleware.Trace("rate_limiter", middleware.RateLimiter(rateLimiter)),
+		middleware.Trace("retry", middleware.Retry(retry)),
+		middleware.Trace("circuit_breaker", middleware.CircuitBreaker(b.cb)),
+		middleware.Trace("cache", middleware.Cache(cache)),
+		middleware.Trace("dedup", middleware.Dedup(dedup)),
+		// validation runs after transform, so requests are validated as sent to the target
+		middleware.Trace("validation", middleware.Validation(validation)),
+		middleware.Trace("transform", middleware.Transform(transform)),
+		middleware.Trace("dead_letter", middleware.DeadLetter(b.dl)),
+		middleware.Trace("metrics", middleware.Metric(met)),
+		middleware.Trace("log", middleware.Log(log)),
+		middleware.TraceSource(cfg.Name, cfg.Source.Kind))
 	return md, nil
 }
+func (b *Binder) newDedupStore(ctx context.Context, cfg config.BindingConfig) (middleware.DedupStore, error) {
+	kind, err := middleware.DedupStoreKind(cfg.Properties)
+	if err != nil {
+		return nil, err
+	}
+	if kind != middleware.DedupStoreTarget {
+		return nil, nil
+	}
+	targetKind, err := cfg.Properties.MustParseString("dedup_store_target_kind")
+	if err != nil {
+		return nil, fmt.Errorf("invalid dedup store target kind, %w", err)
+	}
+	properties, err := cfg.Properties.MustParseJsonMap("dedup_store_target_properties")
+	if err != nil {
+		return nil, fmt.Errorf("invalid dedup store target properties, %w", err)
+	}
+	b.dedupTarget, err = targets.Init(ctx, config.Spec{
+		Name:       fmt.Sprintf("%s-dedup", cfg.Name),
+		Kind:       targetKind,
+		Properties: properties,
+	}, b.log)
+	if err != nil {
+		return nil, fmt.Errorf("error loading dedup store target, %w", err)
+	}
+	return middleware.NewTargetDedupStore(fmt.Sprintf("dedup/%s/", cfg.Name), b.dedupTarget), nil
+}
+
 func (b *Binder) Init(ctx context.Context, cfg config.BindingConfig, exporter *metrics.Exporter) error {
 	b.name = cfg.Name
+	properties, err := secrets.Resolve(cfg.Properties)
+	if err != nil {
+		return fmt.Errorf("error resolving secrets on binding %s, %w", b.name, err)
+	}
+	cfg.Properties = properties
 	log, err := middleware.NewLogMiddleware(cfg.Name, cfg.Properties)
 	if err != nil {
 		return err
 	}
 	b.log = log.Logger
-
-	b.target, err = targets.Init(ctx, cfg.Target, b.log)
+	drainTimeout, err := cfg.Properties.ParseIntWithRange("drain_timeout_milliseconds", defaultDrainTimeoutMilliseconds, 0, math.MaxInt32)
 	if err != nil {
-		return fmt.Errorf("error loading target conntector on binding %s, %w", b.name, err)
+		return fmt.Errorf("invalid drain timeout value on binding %s, %w", b.name, err)
+	}
+	b.drainTimeout = time.Duration(drainTimeout) * time.Millisecond
+
+	if len(cfg.Routes) > 0 {
+		cfg.Target.Kind = targets.RouterKind
+		b.target, err = targets.InitRouter(ctx, cfg.Routes, cfg.Properties, b.log)
+		if err != nil {
+			return fmt.Errorf("error loading routes on binding %s, %w", b.name, err)
+		}
+		b.log.Infof("binding: %s routes: %d initialized successfully", b.name, len(cfg.Routes))
+	} else {
+		b.target, err = targets.Init(ctx, cfg.Target, b.log)
+		if err != nil {
+			return fmt.Errorf("error loading target conntector on binding %s, %w", b.name, err)
+		}
+		b.log.Infof("binding: %s target: initialized successfully", b.name)
 	}
-	b.log.Infof("binding: %s target: initialized successfully", b.name)
-	b.md, err = b.buildMiddleware(cfg, exporter, log)
+	b.md, err = b.buildMiddleware(ctx, cfg, exporter, log)
 	if err != nil {
 		return fmt.Errorf("error loading middlewares on binding %s, %w", b.name, err)
 	}
@@ -79,17 +191,38 @@ func (b *Binder) Start(ctx context.Context) error {
 	b.log.Infof("binding: %s, started successfully", b.name)
 	return nil
 }
+
+// Stop drains and stops the source, then stops the target and the middlewares resources. Resources of a partially
+// initialized binder are released as well, and the first error is returned
 func (b *Binder) Stop() error {
-	err := b.source.Stop()
-	if err != nil {
-		return err
+	var errs []error
+	if b.source != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), b.drainTimeout)
+		defer cancel()
+		if err := b.source.Drain(ctx); err != nil {
+			b.log.Warnf("binding: %s, drain deadline of %s exceeded, stopping with in-flight messages", b.name, b.drainTimeout.String())
+		}
+		errs = append(errs, b.source.Stop())
 	}
-
-	err = b.target.Stop()
-	if err != nil {
-		return err
+	if b.target != nil {
+		errs = append(errs, b.target.Stop())
+	}
+	if b.dl != nil {
+		errs = append(errs, b.dl.Close())
+	}
+	if b.recorder != nil {
+		errs = append(errs, b.recorder.Close())
+	}
+	if b.dedupTarget != nil {
+		errs = append(errs, b.dedupTarget.Stop())
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	if b.log != nil {
+		b.log.Infof("binding: %s, stopped successfully", b.name)
 	}
-	b.log.Infof("binding: %s, stopped successfully", b.name)
-
 	return nil
 }
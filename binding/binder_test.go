@@ -0,0 +1,28 @@
+package binding
+
+import (
+	"context"
+	"github.com/kubemq-hub/kubemq-targets/config"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBinder_StopPartiallyInitialized(t *testing.T) {
+	b := NewBinder()
+	err := b.Init(context.Background(), config.BindingConfig{
+		Name: "b-1",
+		Source: config.Spec{
+			Name: "source",
+			Kind: "kubemq.events",
+		},
+		Target: config.Spec{
+			Name: "target",
+			Kind: "not-a-target",
+		},
+		Properties: types.Metadata{},
+	}, nil)
+	require.Error(t, err)
+	require.NoError(t, b.Stop())
+	require.NoError(t, NewBinder().Stop())
+}
@@ -0,0 +1,172 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/config"
+)
+
+var (
+	ErrBindingNotFound = errors.New("binding not found")
+	ErrBindingExists   = errors.New("binding already exists")
+	ErrInvalidBinding  = errors.New("invalid binding config")
+)
+
+func findBinding(cfg *config.Config, name string) (config.BindingConfig, int) {
+	for i, bindingCfg := range cfg.Bindings {
+		if bindingCfg.Name == name {
+			return bindingCfg, i
+		}
+	}
+	return config.BindingConfig{}, -1
+}
+
+func (s *Service) updateConfig(update func(cfg *config.Config)) error {
+	next := *s.config()
+	next.Bindings = append([]config.BindingConfig{}, next.Bindings...)
+	update(&next)
+	s.setConfig(&next)
+	if !next.ApiPersistBindings {
+		return nil
+	}
+	if err := config.Save(&next); err != nil {
+		return fmt.Errorf("error persisting bindings, %w", err)
+	}
+	return nil
+}
+
+// ApiAuthToken returns the token required for binding management requests
+func (s *Service) ApiAuthToken() string {
+	cfg := s.config()
+	if cfg == nil {
+		return ""
+	}
+	return cfg.ApiAuthToken
+}
+
+// GetBindingStatus returns the status of a single binding
+func (s *Service) GetBindingStatus(name string) (*Status, error) {
+	for _, status := range s.GetStatus() {
+		if status.Binding == name {
+			return status, nil
+		}
+	}
+	return nil, ErrBindingNotFound
+}
+
+// CreateBinding validates, starts and adds a new binding to the running config. A binding which fails to start is
+// kept and retried in the background, and its status reports it as not ready
+func (s *Service) CreateBinding(cfg config.BindingConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("%w, %s", ErrInvalidBinding, err.Error())
+	}
+	s.updateMutex.Lock()
+	defer s.updateMutex.Unlock()
+	if _, index := findBinding(s.config(), cfg.Name); index >= 0 {
+		return ErrBindingExists
+	}
+	if err := s.startBinding(cfg); err != nil {
+		s.log.Errorf("binding: %s, created, retrying to start, %s", cfg.Name, err.Error())
+	}
+	return s.updateConfig(func(next *config.Config) {
+		next.Bindings = append(next.Bindings, cfg)
+	})
+}
+
+// UpdateBinding replaces a binding config and restarts it, the previous config is restored on failure
+func (s *Service) UpdateBinding(name string, cfg config.BindingConfig) error {
+	if cfg.Name == "" {
+		cfg.Name = name
+	}
+	if cfg.Name != name {
+		return fmt.Errorf("%w, binding name cannot be changed", ErrInvalidBinding)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("%w, %s", ErrInvalidBinding, err.Error())
+	}
+	s.updateMutex.Lock()
+	defer s.updateMutex.Unlock()
+	current, index := findBinding(s.config(), name)
+	if index < 0 {
+		return ErrBindingNotFound
+	}
+	if err := s.stopBinding(name); err != nil {
+		return err
+	}
+	_, paused := s.paused.Load(name)
+	s.paused.Delete(name)
+	if err := s.startBinding(cfg); err != nil {
+		_ = s.stopBinding(name)
+		if paused {
+			s.pause(current)
+		} else {
+			_ = s.startBinding(current)
+		}
+		return err
+	}
+	return s.updateConfig(func(next *config.Config) {
+		next.Bindings[index] = cfg
+	})
+}
+
+// DeleteBinding stops a binding and removes it from the running config
+func (s *Service) DeleteBinding(name string) error {
+	s.updateMutex.Lock()
+	defer s.updateMutex.Unlock()
+	_, index := findBinding(s.config(), name)
+	if index < 0 {
+		return ErrBindingNotFound
+	}
+	if err := s.stopBinding(name); err != nil {
+		return err
+	}
+	s.paused.Delete(name)
+	return s.updateConfig(func(next *config.Config) {
+		next.Bindings = append(next.Bindings[:index], next.Bindings[index+1:]...)
+	})
+}
+
+// PauseBinding stops a binding while keeping it in the running config
+func (s *Service) PauseBinding(name string) error {
+	s.updateMutex.Lock()
+	defer s.updateMutex.Unlock()
+	cfg, index := findBinding(s.config(), name)
+	if index < 0 {
+		return ErrBindingNotFound
+	}
+	if _, ok := s.paused.Load(name); ok {
+		return nil
+	}
+	if err := s.stopBinding(name); err != nil {
+		return err
+	}
+	s.pause(cfg)
+	s.log.Infof("binding: %s, paused", name)
+	return nil
+}
+
+func (s *Service) pause(cfg config.BindingConfig) {
+	s.paused.Store(cfg.Name, true)
+	status := newStatus(cfg)
+	status.Paused = true
+	s.bindingStatus.Store(cfg.Name, status)
+}
+
+// ResumeBinding starts a paused binding
+func (s *Service) ResumeBinding(name string) error {
+	s.updateMutex.Lock()
+	defer s.updateMutex.Unlock()
+	cfg, index := findBinding(s.config(), name)
+	if index < 0 {
+		return ErrBindingNotFound
+	}
+	if _, ok := s.paused.Load(name); !ok {
+		return nil
+	}
+	s.paused.Delete(name)
+	if err := s.startBinding(cfg); err != nil {
+		return err
+	}
+	s.log.Infof("binding: %s, resumed", name)
+	return nil
+}
@@ -0,0 +1,132 @@
+package binding
+
+import (
+	"github.com/kubemq-hub/kubemq-targets/config"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"reflect"
+	"time"
+)
+
+type ReloadResult struct {
+	Time      time.Time         `json:"time"`
+	Added     []string          `json:"added"`
+	Removed   []string          `json:"removed"`
+	Restarted []string          `json:"restarted"`
+	Unchanged []string          `json:"unchanged"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+func newReloadResult() *ReloadResult {
+	return &ReloadResult{
+		Time:      time.Now(),
+		Added:     []string{},
+		Removed:   []string{},
+		Restarted: []string{},
+		Unchanged: []string{},
+		Errors:    map[string]string{},
+	}
+}
+
+type bindingsDiff struct {
+	added     []config.BindingConfig
+	removed   []string
+	changed   []config.BindingConfig
+	unchanged []string
+}
+
+func diffBindings(current, next []config.BindingConfig) *bindingsDiff {
+	diff := &bindingsDiff{}
+	currentMap := map[string]config.BindingConfig{}
+	for _, cfg := range current {
+		currentMap[cfg.Name] = cfg
+	}
+	nextMap := map[string]bool{}
+	for _, cfg := range next {
+		nextMap[cfg.Name] = true
+		currentCfg, ok := currentMap[cfg.Name]
+		switch {
+		case !ok:
+			diff.added = append(diff.added, cfg)
+		case !reflect.DeepEqual(normalizeBinding(currentCfg), normalizeBinding(cfg)):
+			diff.changed = append(diff.changed, cfg)
+		default:
+			diff.unchanged = append(diff.unchanged, cfg.Name)
+		}
+	}
+	for _, cfg := range current {
+		if !nextMap[cfg.Name] {
+			diff.removed = append(diff.removed, cfg.Name)
+		}
+	}
+	return diff
+}
+
+// normalizeBinding sets empty values for nil maps and slices so configs loaded from file and from the api compare equal
+func normalizeBinding(cfg config.BindingConfig) config.BindingConfig {
+	cfg.Properties = normalizeMetadata(cfg.Properties)
+	cfg.Source.Properties = normalizeMetadata(cfg.Source.Properties)
+	cfg.Target.Properties = normalizeMetadata(cfg.Target.Properties)
+	routes := []config.RouteConfig{}
+	for _, route := range cfg.Routes {
+		route.Target.Properties = normalizeMetadata(route.Target.Properties)
+		routes = append(routes, route)
+	}
+	cfg.Routes = routes
+	return cfg
+}
+
+func normalizeMetadata(meta types.Metadata) types.Metadata {
+	if meta == nil {
+		return types.NewMetadata()
+	}
+	return meta
+}
+
+// Reload applies a new configuration by adding, removing and restarting only the bindings which were changed
+func (s *Service) Reload(cfg *config.Config) *ReloadResult {
+	s.updateMutex.Lock()
+	defer s.updateMutex.Unlock()
+	result := newReloadResult()
+	diff := diffBindings(s.config().Bindings, cfg.Bindings)
+	for _, name := range diff.removed {
+		s.paused.Delete(name)
+		if err := s.stopBinding(name); err != nil {
+			result.Errors[name] = err.Error()
+			s.log.Errorf("failed to remove binding %s on reload, %s", name, err.Error())
+		}
+		result.Removed = append(result.Removed, name)
+	}
+	for _, bindingCfg := range diff.changed {
+		s.paused.Delete(bindingCfg.Name)
+		if err := s.stopBinding(bindingCfg.Name); err != nil {
+			result.Errors[bindingCfg.Name] = err.Error()
+			s.log.Errorf("failed to stop binding %s on reload, %s", bindingCfg.Name, err.Error())
+		}
+	}
+	s.setConfig(cfg)
+	for _, bindingCfg := range diff.changed {
+		if err := s.startBinding(bindingCfg); err != nil {
+			result.Errors[bindingCfg.Name] = err.Error()
+		}
+		result.Restarted = append(result.Restarted, bindingCfg.Name)
+	}
+	for _, bindingCfg := range diff.added {
+		if err := s.startBinding(bindingCfg); err != nil {
+			result.Errors[bindingCfg.Name] = err.Error()
+		}
+		result.Added = append(result.Added, bindingCfg.Name)
+	}
+	result.Unchanged = append(result.Unchanged, diff.unchanged...)
+	s.log.Infof("bindings reloaded, added: %d, removed: %d, restarted: %d, unchanged: %d, errors: %d", len(result.Added), len(result.Removed), len(result.Restarted), len(result.Unchanged), len(result.Errors))
+	s.lastReload.Store(result)
+	return result
+}
+
+// LastReload returns the result of the last configuration reload, nil if no reload took place
+func (s *Service) LastReload() *ReloadResult {
+	val := s.lastReload.Load()
+	if val == nil {
+		return nil
+	}
+	return val.(*ReloadResult)
+}
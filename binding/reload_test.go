@@ -0,0 +1,81 @@
+package binding
+
+import (
+	"github.com/kubemq-hub/kubemq-targets/config"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newTestBindingConfig(name, channel string) config.BindingConfig {
+	return config.BindingConfig{
+		Name: name,
+		Source: config.Spec{
+			Name:       "source",
+			Kind:       "kubemq.queue",
+			Properties: map[string]string{"channel": channel},
+		},
+		Target: config.Spec{
+			Name:       "target",
+			Kind:       "echo",
+			Properties: map[string]string{},
+		},
+		Properties: map[string]string{},
+	}
+}
+
+func bindingNames(list []config.BindingConfig) []string {
+	var names []string
+	for _, cfg := range list {
+		names = append(names, cfg.Name)
+	}
+	return names
+}
+
+func TestService_diffBindings(t *testing.T) {
+	tests := []struct {
+		name          string
+		current       []config.BindingConfig
+		next          []config.BindingConfig
+		wantAdded     []string
+		wantRemoved   []string
+		wantChanged   []string
+		wantUnchanged []string
+	}{
+		{
+			name:      "start from empty",
+			current:   nil,
+			next:      []config.BindingConfig{newTestBindingConfig("b-1", "c-1"), newTestBindingConfig("b-2", "c-2")},
+			wantAdded: []string{"b-1", "b-2"},
+		},
+		{
+			name:          "no changes",
+			current:       []config.BindingConfig{newTestBindingConfig("b-1", "c-1"), newTestBindingConfig("b-2", "c-2")},
+			next:          []config.BindingConfig{newTestBindingConfig("b-2", "c-2"), newTestBindingConfig("b-1", "c-1")},
+			wantUnchanged: []string{"b-2", "b-1"},
+		},
+		{
+			name:          "add, remove and change",
+			current:       []config.BindingConfig{newTestBindingConfig("b-1", "c-1"), newTestBindingConfig("b-2", "c-2"), newTestBindingConfig("b-3", "c-3")},
+			next:          []config.BindingConfig{newTestBindingConfig("b-1", "c-1"), newTestBindingConfig("b-2", "c-2-changed"), newTestBindingConfig("b-4", "c-4")},
+			wantAdded:     []string{"b-4"},
+			wantRemoved:   []string{"b-3"},
+			wantChanged:   []string{"b-2"},
+			wantUnchanged: []string{"b-1"},
+		},
+		{
+			name:        "remove all",
+			current:     []config.BindingConfig{newTestBindingConfig("b-1", "c-1")},
+			next:        nil,
+			wantRemoved: []string{"b-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := diffBindings(tt.current, tt.next)
+			require.Equal(t, tt.wantAdded, bindingNames(diff.added))
+			require.Equal(t, tt.wantRemoved, diff.removed)
+			require.Equal(t, tt.wantChanged, bindingNames(diff.changed))
+			require.Equal(t, tt.wantUnchanged, diff.unchanged)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package binding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/config"
+	"github.com/kubemq-hub/kubemq-targets/middleware"
+	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/targets"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"sort"
+)
+
+// ReplayResult is the result of a replayed record, with the differences between the recorded and the replayed responses.
+// Records with redacted request metadata are not replayed, and their redacted keys are listed
+type ReplayResult struct {
+	Index    int            `json:"index"`
+	Request  *types.Request `json:"request"`
+	Recorded *Response      `json:"recorded"`
+	Replayed *Response      `json:"replayed"`
+	Diff     []string       `json:"diff,omitempty"`
+	Redacted []string       `json:"redacted,omitempty"`
+}
+
+type ReplayReport struct {
+	Total      int             `json:"total"`
+	Matched    int             `json:"matched"`
+	Mismatched int             `json:"mismatched"`
+	Skipped    int             `json:"skipped"`
+	Results    []*ReplayResult `json:"results"`
+}
+
+// Replay sends the recorded requests to the target of the binding, without the binding middlewares, and compares the
+// responses. Requests with redacted metadata values are skipped, as their original values are not recorded
+func Replay(ctx context.Context, cfg config.BindingConfig, records []*middleware.Record, log *logger.Logger) (*ReplayReport, error) {
+	var target targets.Target
+	var err error
+	if len(cfg.Routes) > 0 {
+		target, err = targets.InitRouter(ctx, cfg.Routes, cfg.Properties, log)
+	} else {
+		target, err = targets.Init(ctx, cfg.Target, log)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading target conntector on binding %s, %w", cfg.Name, err)
+	}
+	defer func() {
+		_ = target.Stop()
+	}()
+	report := &ReplayReport{}
+	for i, record := range records {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		report.Total++
+		if redacted := record.RedactedKeys(); len(redacted) > 0 {
+			report.Skipped++
+			report.Results = append(report.Results, &ReplayResult{
+				Index:    i + 1,
+				Request:  record.Request,
+				Recorded: recordResponse(record.Response, record.Error),
+				Redacted: redacted,
+			})
+			continue
+		}
+		result := replayRecord(ctx, target, record)
+		result.Index = i + 1
+		if len(result.Diff) == 0 {
+			report.Matched++
+		} else {
+			report.Mismatched++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+func replayRecord(ctx context.Context, target targets.Target, record *middleware.Record) *ReplayResult {
+	result := &ReplayResult{
+		Request:  record.Request,
+		Recorded: recordResponse(record.Response, record.Error),
+	}
+	// targets may change the request metadata, so the replayed request is a copy of the recorded one
+	request := types.NewRequest().SetData(record.Request.Data)
+	for key, value := range record.Request.Metadata {
+		request.Metadata[key] = value
+	}
+	resp, err := target.Do(ctx, request)
+	errStr := ""
+	if err != nil {
+		errStr = err.Error()
+	}
+	result.Replayed = recordResponse(resp, errStr)
+	result.Diff = diffResponses(record.Response, record.Error, resp, errStr)
+	return result
+}
+
+func recordResponse(resp *types.Response, errStr string) *Response {
+	if errStr != "" {
+		return toResponse(types.NewResponse().SetError(fmt.Errorf("%s", errStr)))
+	}
+	if resp == nil {
+		return nil
+	}
+	return toResponse(resp)
+}
+
+// diffResponses lists the differences between the recorded and replayed responses, json data is compared by value
+func diffResponses(recorded *types.Response, recordedErr string, replayed *types.Response, replayedErr string) []string {
+	var diff []string
+	if recordedErr != replayedErr {
+		diff = append(diff, fmt.Sprintf("error: recorded %q, replayed %q", recordedErr, replayedErr))
+	}
+	if recorded == nil || replayed == nil {
+		if (recorded == nil) != (replayed == nil) {
+			diff = append(diff, fmt.Sprintf("response: recorded %t, replayed %t", recorded != nil, replayed != nil))
+		}
+		return diff
+	}
+	if recorded.IsError != replayed.IsError {
+		diff = append(diff, fmt.Sprintf("is_error: recorded %t, replayed %t", recorded.IsError, replayed.IsError))
+	}
+	if recorded.Error != replayed.Error {
+		diff = append(diff, fmt.Sprintf("response error: recorded %q, replayed %q", recorded.Error, replayed.Error))
+	}
+	keys := map[string]bool{}
+	for key := range recorded.Metadata {
+		keys[key] = true
+	}
+	for key := range replayed.Metadata {
+		keys[key] = true
+	}
+	var sorted []string
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		recordedValue, recordedOk := recorded.Metadata[key]
+		replayedValue, replayedOk := replayed.Metadata[key]
+		switch {
+		case !recordedOk:
+			diff = append(diff, fmt.Sprintf("metadata.%s: not recorded, replayed %q", key, replayedValue))
+		case !replayedOk:
+			diff = append(diff, fmt.Sprintf("metadata.%s: recorded %q, not replayed", key, recordedValue))
+		case recordedValue != replayedValue:
+			diff = append(diff, fmt.Sprintf("metadata.%s: recorded %q, replayed %q", key, recordedValue, replayedValue))
+		}
+	}
+	if !equalData(recorded.Data, replayed.Data) {
+		diff = append(diff, fmt.Sprintf("data: recorded %s, replayed %s", string(recorded.Data), string(replayed.Data)))
+	}
+	return diff
+}
+
+func equalData(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var aValue, bValue interface{}
+	if json.Unmarshal(a, &aValue) != nil || json.Unmarshal(b, &bValue) != nil {
+		return false
+	}
+	aJson, _ := json.Marshal(aValue)
+	bJson, _ := json.Marshal(bValue)
+	return bytes.Equal(aJson, bJson)
+}
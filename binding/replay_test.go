@@ -0,0 +1,82 @@
+package binding
+
+import (
+	"context"
+	"github.com/kubemq-hub/kubemq-targets/middleware"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	host, _ := os.Hostname()
+	tests := []struct {
+		name           string
+		records        []*middleware.Record
+		wantMatched    int
+		wantMismatched int
+		wantSkipped    int
+	}{
+		{
+			name: "matched responses",
+			records: []*middleware.Record{
+				{
+					Request:  types.NewRequest().SetMetadataKeyValue("key", "1").SetData([]byte(`{"a":1,"b":2}`)),
+					Response: types.NewResponse().SetMetadataKeyValue("key", "1").SetMetadataKeyValue("host", host).SetData([]byte(`{"b":2,"a":1}`)),
+				},
+				{
+					Request:  types.NewRequest().SetMetadataKeyValue("key", "2").SetData([]byte("data")),
+					Response: types.NewResponse().SetMetadataKeyValue("key", "2").SetMetadataKeyValue("host", host).SetData([]byte("data")),
+				},
+			},
+			wantMatched: 2,
+		},
+		{
+			name: "mismatched responses",
+			records: []*middleware.Record{
+				{
+					Request:  types.NewRequest().SetMetadataKeyValue("key", "1").SetData([]byte("data")),
+					Response: types.NewResponse().SetMetadataKeyValue("key", "1").SetMetadataKeyValue("host", host).SetData([]byte("other-data")),
+				},
+				{
+					Request: types.NewRequest().SetMetadataKeyValue("key", "2"),
+					Error:   "some-error",
+				},
+			},
+			wantMismatched: 2,
+		},
+		{
+			name: "redacted requests",
+			records: []*middleware.Record{
+				{
+					Request:  types.NewRequest().SetMetadataKeyValue("key", "1").SetMetadataKeyValue("password", "[redacted]"),
+					Response: types.NewResponse().SetMetadataKeyValue("key", "1").SetMetadataKeyValue("host", host),
+				},
+				{
+					Request:  types.NewRequest().SetMetadataKeyValue("key", "2"),
+					Response: types.NewResponse().SetMetadataKeyValue("key", "2").SetMetadataKeyValue("host", host),
+				},
+			},
+			wantMatched: 1,
+			wantSkipped: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Replay(context.Background(), newTestBindingConfig("b-1", "c-1"), tt.records, nil)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.records), report.Total)
+			require.Equal(t, tt.wantMatched, report.Matched)
+			require.Equal(t, tt.wantMismatched, report.Mismatched)
+			require.Equal(t, tt.wantSkipped, report.Skipped)
+			for _, result := range report.Results {
+				require.Equal(t, len(result.Diff) > 0, tt.wantMismatched > 0)
+				if len(result.Redacted) > 0 {
+					require.Equal(t, []string{"password"}, result.Redacted)
+					require.Nil(t, result.Replayed)
+				}
+			}
+		})
+	}
+}
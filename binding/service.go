@@ -9,6 +9,7 @@ import (
 	"github.com/kubemq-hub/kubemq-targets/types"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,7 +24,12 @@ type Service struct {
 	currentCtx        context.Context
 	currentCancelFunc context.CancelFunc
 	bindingStatus     sync.Map
+	bindingCancels    sync.Map
 	cfg               *config.Config
+	cfgMutex          sync.RWMutex
+	updateMutex       sync.Mutex
+	paused            sync.Map
+	lastReload        atomic.Value
 }
 
 func New() (*Service, error) {
@@ -41,50 +47,89 @@ func New() (*Service, error) {
 }
 func (s *Service) Start(ctx context.Context, cfg *config.Config) error {
 	s.currentCtx, s.currentCancelFunc = context.WithCancel(ctx)
-	s.cfg = cfg
+	s.setConfig(cfg)
 	if len(cfg.Bindings) == 0 {
 		return nil
 	}
 	for _, bindingCfg := range cfg.Bindings {
-		go func(ctx context.Context, cfg config.BindingConfig) {
-			err := s.Add(ctx, cfg)
-			if err == nil {
-				return
-			} else {
-				s.log.Errorf("failed to initialized binding, %s", err.Error())
-			}
-			count := 0
-			for {
-				select {
-				case <-time.After(addRetryInterval):
-					count++
-					err := s.Add(ctx, cfg)
-					if err != nil {
-						s.log.Errorf("failed to initialized binding: %s, attempt: %d, error: %s", cfg.Name, count, err.Error())
-					} else {
-						return
-					}
-				case <-ctx.Done():
+		go func(cfg config.BindingConfig) {
+			_ = s.startBinding(cfg)
+		}(bindingCfg)
+	}
+	return nil
+}
+
+func (s *Service) startBinding(cfg config.BindingConfig) error {
+	ctx, cancel := context.WithCancel(s.currentCtx)
+	s.bindingCancels.Store(cfg.Name, cancel)
+	err := s.Add(ctx, cfg)
+	if err == nil {
+		return nil
+	}
+	s.log.Errorf("failed to initialized binding, %s", err.Error())
+	go func() {
+		count := 0
+		for {
+			select {
+			case <-time.After(addRetryInterval):
+				count++
+				err := s.Add(ctx, cfg)
+				if err != nil {
+					s.log.Errorf("failed to initialized binding: %s, attempt: %d, error: %s", cfg.Name, count, err.Error())
+				} else {
 					return
 				}
+			case <-ctx.Done():
+				return
 			}
+		}
+	}()
+	return err
+}
 
-		}(s.currentCtx, bindingCfg)
+func (s *Service) stopBinding(name string) error {
+	var err error
+	if _, ok := s.bindings.Load(name); ok {
+		err = s.Remove(name)
+	} else {
+		s.bindingStatus.Delete(name)
 	}
-	return nil
+	if val, ok := s.bindingCancels.Load(name); ok {
+		val.(context.CancelFunc)()
+		s.bindingCancels.Delete(name)
+	}
+	return err
+}
+
+func (s *Service) config() *config.Config {
+	s.cfgMutex.RLock()
+	defer s.cfgMutex.RUnlock()
+	return s.cfg
 }
 
+func (s *Service) setConfig(cfg *config.Config) {
+	s.cfgMutex.Lock()
+	defer s.cfgMutex.Unlock()
+	s.cfg = cfg
+}
+
+// Stop drains and removes all bindings concurrently and only then cancels the bindings context
 func (s *Service) Stop() {
-	s.currentCancelFunc()
+	wg := sync.WaitGroup{}
 	s.bindings.Range(func(key, value interface{}) bool {
 		binder := value.(*Binder)
-		err := s.Remove(binder.name)
-		if err != nil {
-			s.log.Error(err)
-		}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			err := s.Remove(name)
+			if err != nil {
+				s.log.Error(err)
+			}
+		}(binder.name)
 		return true
 	})
-
+	wg.Wait()
+	s.currentCancelFunc()
 }
 func (s *Service) Add(ctx context.Context, cfg config.BindingConfig) error {
 
@@ -93,15 +138,23 @@ func (s *Service) Add(ctx context.Context, cfg config.BindingConfig) error {
 	s.bindingStatus.Store(cfg.Name, status)
 	err := binder.Init(ctx, cfg, s.exporter)
 	if err != nil {
+		// a failed binding is retried, so the resources initialized so far are released
+		_ = binder.Stop()
 		return err
 	}
 	err = binder.Start(ctx)
 	if err != nil {
+		_ = binder.Stop()
 		return err
 	}
+	if ctx.Err() != nil {
+		_ = binder.Stop()
+		return ctx.Err()
+	}
 	s.bindings.Store(cfg.Name, binder)
-	status.Ready = true
-	s.bindingStatus.Store(cfg.Name, status)
+	ready := *status
+	ready.Ready = true
+	s.bindingStatus.Store(cfg.Name, &ready)
 	return nil
 }
 
@@ -117,6 +170,7 @@ func (s *Service) Remove(name string) error {
 	}
 	s.bindings.Delete(name)
 	s.bindingStatus.Delete(name)
+	s.exporter.UnregisterLatency(name)
 	return nil
 }
 
@@ -128,10 +182,15 @@ func (s *Service) Stats() []*metrics.Report {
 }
 func (s *Service) GetStatus() []*Status {
 	var list []*Status
-	for _, binding := range s.cfg.Bindings {
+	for _, binding := range s.config().Bindings {
 		val, ok := s.bindingStatus.Load(binding.Name)
 		if ok {
-			list = append(list, val.(*Status))
+			// stored statuses are shared between callers, so the circuit breaker state is set on a copy
+			status := *val.(*Status)
+			if binder, ok := s.bindings.Load(binding.Name); ok && binder.(*Binder).cb != nil {
+				status.CircuitBreaker = string(binder.(*Binder).cb.State())
+			}
+			list = append(list, &status)
 		}
 	}
 	return list
@@ -3,29 +3,80 @@ package binding
 import (
 	"fmt"
 	"github.com/kubemq-hub/kubemq-targets/config"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
+	"github.com/kubemq-hub/kubemq-targets/sources"
+	"github.com/kubemq-hub/kubemq-targets/targets"
+	"sync"
 )
 
+var connectorsOnce sync.Once
+
 type Status struct {
 	Binding          string            `json:"binding"`
 	Ready            bool              `json:"ready"`
+	Paused           bool              `json:"paused,omitempty"`
 	SourceType       string            `json:"source_type"`
 	SourceConnection string            `json:"source_connection"`
 	SourceConfig     map[string]string `json:"source_config"`
 	TargetType       string            `json:"target_type"`
 	TargetConfig     map[string]string `json:"target_config"`
+	Routes           []*RouteStatus    `json:"routes,omitempty"`
+	CircuitBreaker   string            `json:"circuit_breaker,omitempty"`
+}
+
+type RouteStatus struct {
+	Route        string            `json:"route"`
+	Condition    string            `json:"condition"`
+	Default      bool              `json:"default"`
+	TargetType   string            `json:"target_type"`
+	TargetConfig map[string]string `json:"target_config"`
+}
+
+// redact redacts the secret properties of a connector kind, the connector definitions are built once before, so their
+// secret properties are marked
+func redact(kind string, properties map[string]string) map[string]string {
+	connectorsOnce.Do(func() {
+		_ = sources.Connectors()
+		_ = targets.Connectors()
+	})
+	return secrets.Redact(kind, properties)
 }
 
 func getSourceConnection(properties map[string]string) string {
 	return fmt.Sprintf("%s/%s", properties["address"], properties["channel"])
 }
 func newStatus(cfg config.BindingConfig) *Status {
+	if len(cfg.Routes) > 0 {
+		return newRoutesStatus(cfg)
+	}
 	return &Status{
 		Binding:          cfg.Name,
 		Ready:            false,
 		SourceType:       cfg.Source.Kind,
 		SourceConnection: getSourceConnection(cfg.Source.Properties),
-		SourceConfig:     cfg.Source.Properties,
+		SourceConfig:     redact(sources.ConnectorKind(cfg.Source.Kind), cfg.Source.Properties),
 		TargetType:       cfg.Target.Kind,
-		TargetConfig:     cfg.Target.Properties,
+		TargetConfig:     redact(cfg.Target.Kind, cfg.Target.Properties),
+	}
+}
+
+func newRoutesStatus(cfg config.BindingConfig) *Status {
+	status := &Status{
+		Binding:          cfg.Name,
+		Ready:            false,
+		SourceType:       cfg.Source.Kind,
+		SourceConnection: getSourceConnection(cfg.Source.Properties),
+		SourceConfig:     redact(sources.ConnectorKind(cfg.Source.Kind), cfg.Source.Properties),
+		TargetType:       targets.RouterKind,
+	}
+	for _, route := range cfg.Routes {
+		status.Routes = append(status.Routes, &RouteStatus{
+			Route:        route.Name,
+			Condition:    route.Condition,
+			Default:      route.Default,
+			TargetType:   route.Target.Kind,
+			TargetConfig: redact(route.Target.Kind, route.Target.Properties),
+		})
 	}
+	return status
 }
@@ -9,6 +9,7 @@ type BindingConfig struct {
 	Name       string         `json:"name"`
 	Source     Spec           `json:"source"`
 	Target     Spec           `json:"target"`
+	Routes     []RouteConfig  `json:"routes,omitempty"`
 	Properties types.Metadata `json:"properties"`
 }
 
@@ -19,6 +20,15 @@ func (b BindingConfig) Validate() error {
 	if err := b.Source.Validate(); err != nil {
 		return fmt.Errorf("binding source error, %w", err)
 	}
+	if len(b.Routes) > 0 {
+		if b.Target.Kind != "" {
+			return fmt.Errorf("binding cannot have both target and routes")
+		}
+		if err := validateRoutes(b.Routes); err != nil {
+			return fmt.Errorf("binding routes error, %w", err)
+		}
+		return nil
+	}
 	if err := b.Target.Validate(); err != nil {
 		return fmt.Errorf("binding target error, %w", err)
 	}
@@ -25,9 +25,13 @@ var logr = logger.NewLogger("config")
 var lastConf *Config
 
 type Config struct {
-	Bindings []BindingConfig `json:"bindings"`
-	ApiPort  int             `json:"apiPort"`
-	LogLevel string          `json:"logLevel"`
+	Bindings           []BindingConfig `json:"bindings"`
+	ApiPort            int             `json:"apiPort"`
+	ApiAuthToken       string          `json:"apiAuthToken"`
+	ApiPersistBindings bool            `json:"apiPersistBindings"`
+	LogLevel           string          `json:"logLevel"`
+	SecretsDir         string          `json:"secretsDir"`
+	Tracing            TracingConfig   `json:"tracing"`
 }
 
 func SetConfigFile(filename string) {
@@ -53,6 +57,9 @@ func (c *Config) Validate() error {
 	if c.ApiPort == 0 {
 		c.ApiPort = defaultApiPort
 	}
+	if err := c.Tracing.Validate(); err != nil {
+		return err
+	}
 	exitedBindings := map[string]string{}
 	for _, binding := range c.Bindings {
 		if err := binding.Validate(); err != nil {
@@ -188,3 +195,27 @@ func Load(cfgCh chan *Config) (*Config, error) {
 	})
 	return cfg, err
 }
+
+// Save writes the config back to the loaded config file
+func Save(cfg *Config) error {
+	filename := viper.ConfigFileUsed()
+	if filename == "" {
+		return fmt.Errorf("no config file was loaded")
+	}
+	var data []byte
+	var err error
+	if strings.HasSuffix(filename, ".json") {
+		data, err = json.MarshalIndent(cfg, "", "  ")
+	} else {
+		data, err = yaml.Marshal(cfg)
+	}
+	if err != nil {
+		return fmt.Errorf("error encoding config file, %w", err)
+	}
+	/* #nosec */
+	err = ioutil.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing config file, %w", err)
+	}
+	return nil
+}
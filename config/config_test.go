@@ -98,6 +98,73 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid config - routes",
+			Bindings: []BindingConfig{
+				{
+					Name: "binding-1",
+					Source: Spec{
+						Name: "source-1",
+						Kind: "source-1",
+					},
+					Routes: []RouteConfig{
+						{
+							Name:      "route-1",
+							Condition: "{{eq .Metadata.type \"a\"}}",
+							Target:    Spec{Kind: "target-1"},
+						},
+						{
+							Name:    "route-2",
+							Default: true,
+							Target:  Spec{Kind: "target-2"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid config - routes and target",
+			Bindings: []BindingConfig{
+				{
+					Name: "binding-1",
+					Source: Spec{
+						Name: "source-1",
+						Kind: "source-1",
+					},
+					Target: Spec{
+						Kind: "target-1",
+					},
+					Routes: []RouteConfig{
+						{
+							Name:    "route-1",
+							Default: true,
+							Target:  Spec{Kind: "target-1"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid config - route without condition",
+			Bindings: []BindingConfig{
+				{
+					Name: "binding-1",
+					Source: Spec{
+						Name: "source-1",
+						Kind: "source-1",
+					},
+					Routes: []RouteConfig{
+						{
+							Name:   "route-1",
+							Target: Spec{Kind: "target-1"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
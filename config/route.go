@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+)
+
+type RouteConfig struct {
+	Name      string `json:"name"`
+	Condition string `json:"condition"`
+	Default   bool   `json:"default"`
+	Target    Spec   `json:"target"`
+}
+
+func (r RouteConfig) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("route must have name")
+	}
+	if !r.Default && r.Condition == "" {
+		return fmt.Errorf("route %s must have condition or be a default route", r.Name)
+	}
+	if err := r.Target.Validate(); err != nil {
+		return fmt.Errorf("route %s target error, %w", r.Name, err)
+	}
+	return nil
+}
+
+func validateRoutes(routes []RouteConfig) error {
+	names := map[string]string{}
+	defaults := 0
+	for _, route := range routes {
+		if err := route.Validate(); err != nil {
+			return err
+		}
+		if _, ok := names[route.Name]; ok {
+			return fmt.Errorf("duplicated route names found: %s", route.Name)
+		}
+		names[route.Name] = route.Name
+		if route.Default {
+			defaults++
+		}
+	}
+	if defaults > 1 {
+		return fmt.Errorf("only one default route is allowed")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+)
+
+const (
+	TracingExporterNone   = ""
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+
+	defaultTracingServiceName = "kubemq-targets"
+)
+
+type TracingConfig struct {
+	Exporter    string  `json:"exporter"`
+	Endpoint    string  `json:"endpoint"`
+	Insecure    bool    `json:"insecure"`
+	ServiceName string  `json:"serviceName"`
+	SampleRatio float64 `json:"sampleRatio"`
+}
+
+func (t *TracingConfig) Validate() error {
+	switch t.Exporter {
+	case TracingExporterNone, TracingExporterStdout:
+	case TracingExporterOTLP:
+		if t.Endpoint == "" {
+			return fmt.Errorf("tracing otlp exporter must have endpoint")
+		}
+	default:
+		return fmt.Errorf("invalid tracing exporter %s", t.Exporter)
+	}
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1")
+	}
+	if t.SampleRatio == 0 {
+		t.SampleRatio = 1
+	}
+	if t.ServiceName == "" {
+		t.ServiceName = defaultTracingServiceName
+	}
+	return nil
+}
@@ -11,15 +11,19 @@ import (
 	"github.com/kubemq-hub/kubemq-targets/api"
 	"github.com/kubemq-hub/kubemq-targets/binding"
 	"github.com/kubemq-hub/kubemq-targets/config"
+	"github.com/kubemq-hub/kubemq-targets/middleware"
 	"github.com/kubemq-hub/kubemq-targets/pkg/browser"
 	"github.com/kubemq-hub/kubemq-targets/pkg/builder"
 	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
+	"github.com/kubemq-hub/kubemq-targets/pkg/tracing"
 	"github.com/kubemq-hub/kubemq-targets/sources"
 	"github.com/kubemq-hub/kubemq-targets/targets"
 	"io/ioutil"
 
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 )
 
@@ -36,6 +40,8 @@ var (
 	svcFlag          = flag.String("service", "", "control the kubemq-targets service")
 	svcUsername      = flag.String("username", "", "kubemq-targets service username")
 	svcPassword      = flag.String("password", "", "kubemq-targets service password")
+	replayFile       = flag.String("replay", "", "replay a recording file against the binding target and diff the responses")
+	replayBinding    = flag.String("binding", "", "binding name of the replayed recording file")
 )
 
 func saveManifest() error {
@@ -74,6 +80,47 @@ func downloadUrl() error {
 	}
 	return nil
 }
+func runReplay() error {
+	cfg, err := config.Load(make(chan *config.Config, 1))
+	if err != nil {
+		return err
+	}
+	var bindingCfg *config.BindingConfig
+	for i := range cfg.Bindings {
+		if cfg.Bindings[i].Name == *replayBinding {
+			bindingCfg = &cfg.Bindings[i]
+		}
+	}
+	if bindingCfg == nil {
+		return fmt.Errorf("binding %s not found", *replayBinding)
+	}
+	file, err := os.Open(*replayFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	records, err := middleware.ReadRecords(file)
+	if err != nil {
+		return err
+	}
+	report, err := binding.Replay(context.Background(), *bindingCfg, records, log)
+	if err != nil {
+		return err
+	}
+	for _, result := range report.Results {
+		if len(result.Redacted) > 0 {
+			log.Infof("record %d: skipped, redacted metadata keys: %s", result.Index, strings.Join(result.Redacted, ","))
+		}
+		for _, diff := range result.Diff {
+			log.Infof("record %d: %s", result.Index, diff)
+		}
+	}
+	log.Infof("replayed %d records, matched: %d, mismatched: %d, skipped: %d", report.Total, report.Matched, report.Mismatched, report.Skipped)
+	if report.Mismatched > 0 {
+		return fmt.Errorf("%d replayed responses are different than recorded", report.Mismatched)
+	}
+	return nil
+}
 func runInteractive(serviceExit chan bool) error {
 	var gracefulShutdown = make(chan os.Signal, 1)
 	signal.Notify(gracefulShutdown, syscall.SIGTERM)
@@ -90,6 +137,16 @@ func runInteractive(serviceExit chan bool) error {
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	if cfg.SecretsDir != "" {
+		secrets.Register("secret", secrets.NewDirectoryProvider(cfg.SecretsDir))
+	}
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = shutdownTracing(context.Background())
+	}()
 	bindingsService, err := binding.New()
 	if err != nil {
 		return err
@@ -103,6 +160,7 @@ func runInteractive(serviceExit chan bool) error {
 	if err != nil {
 		return err
 	}
+	apiPort := cfg.ApiPort
 	for {
 		select {
 		case newConfig := <-configCh:
@@ -110,10 +168,9 @@ func runInteractive(serviceExit chan bool) error {
 			if err != nil {
 				return fmt.Errorf("error on validation new config file: %s", err.Error())
 			}
-			bindingsService.Stop()
-			err = bindingsService.Start(ctx, newConfig)
-			if err != nil {
-				return fmt.Errorf("error on restarting service with new config file: %s", err.Error())
+			bindingsService.Reload(newConfig)
+			if newConfig.ApiPort == apiPort {
+				continue
 			}
 			if apiServer != nil {
 				err = apiServer.Stop()
@@ -121,11 +178,11 @@ func runInteractive(serviceExit chan bool) error {
 					return fmt.Errorf("error on shutdown api server: %s", err.Error())
 				}
 			}
-
 			apiServer, err = api.Start(ctx, newConfig.ApiPort, bindingsService)
 			if err != nil {
 				return fmt.Errorf("error on start api server: %s", err.Error())
 			}
+			apiPort = newConfig.ApiPort
 		case <-gracefulShutdown:
 			_ = apiServer.Stop()
 			bindingsService.Stop()
@@ -156,6 +213,14 @@ func preRun() {
 			os.Exit(0)
 		}
 	}
+	if *replayFile != "" {
+		err := runReplay()
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 	if *buildUrl != "" {
 		err := downloadUrl()
 		if err != nil {
@@ -10,6 +10,8 @@ import (
 	"github.com/kubemq-hub/kubemq-targets/binding"
 	"github.com/kubemq-hub/kubemq-targets/config"
 	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
+	"github.com/kubemq-hub/kubemq-targets/pkg/tracing"
 	"os"
 	"os/signal"
 	"syscall"
@@ -40,6 +42,16 @@ func run() error {
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	if cfg.SecretsDir != "" {
+		secrets.Register("secret", secrets.NewDirectoryProvider(cfg.SecretsDir))
+	}
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = shutdownTracing(context.Background())
+	}()
 	bindingsService, err := binding.New()
 	if err != nil {
 		return err
@@ -53,6 +65,7 @@ func run() error {
 	if err != nil {
 		return err
 	}
+	apiPort := cfg.ApiPort
 	for {
 		select {
 		case newConfig := <-configCh:
@@ -61,10 +74,9 @@ func run() error {
 				return fmt.Errorf("error on validation new config file: %s", err.Error())
 
 			}
-			bindingsService.Stop()
-			err = bindingsService.Start(ctx, newConfig)
-			if err != nil {
-				return fmt.Errorf("error on restarting service with new config file: %s", err.Error())
+			bindingsService.Reload(newConfig)
+			if newConfig.ApiPort == apiPort {
+				continue
 			}
 			if apiServer != nil {
 				err = apiServer.Stop()
@@ -72,11 +84,11 @@ func run() error {
 					return fmt.Errorf("error on shutdown api server: %s", err.Error())
 				}
 			}
-
 			apiServer, err = api.Start(ctx, newConfig.ApiPort, bindingsService)
 			if err != nil {
 				return fmt.Errorf("error on start api server: %s", err.Error())
 			}
+			apiPort = newConfig.ApiPort
 		case <-gracefulShutdown:
 			_ = apiServer.Stop()
 			bindingsService.Stop()
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"go.opentelemetry.io/otel/trace"
+	"math"
+	"sync"
+	"time"
+)
+
+// batchTarget is implemented by targets supporting batch execution, see targets.BatchTarget
+type batchTarget interface {
+	DoBatch(ctx context.Context, requests []*types.Request) ([]*types.Response, []error, error)
+}
+
+type batchResult struct {
+	resp *types.Response
+	err  error
+}
+
+type batchItem struct {
+	ctx      context.Context
+	request  *types.Request
+	resultCh chan batchResult
+}
+
+type BatchMiddleware struct {
+	ctx         context.Context
+	maxRequests int
+	maxWait     time.Duration
+	target      batchTarget
+	mu          sync.Mutex
+	pending     []*batchItem
+	generation  int64
+}
+
+// NewBatchMiddleware creates a batch middleware for target, batching is disabled when target does not support batch execution.
+// Batches are executed with ctx, the binding context
+func NewBatchMiddleware(ctx context.Context, meta types.Metadata, target Middleware) (*BatchMiddleware, error) {
+	maxRequests, err := meta.ParseIntWithRange("batch_max_requests", 0, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch max requests value, %w", err)
+	}
+	maxWait, err := meta.ParseIntWithRange("batch_max_wait_milliseconds", 100, 1, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch max wait milliseconds value, %w", err)
+	}
+	b := &BatchMiddleware{
+		ctx:         ctx,
+		maxRequests: maxRequests,
+		maxWait:     time.Duration(maxWait) * time.Millisecond,
+	}
+	if bt, ok := target.(batchTarget); ok {
+		b.target = bt
+	}
+	return b, nil
+}
+
+func (b *BatchMiddleware) enabled() bool {
+	return b.maxRequests > 1 && b.target != nil
+}
+
+// add queues the request and returns its result channel, the batch is flushed when max requests is reached or max wait is passed
+func (b *BatchMiddleware) add(ctx context.Context, request *types.Request) chan batchResult {
+	item := &batchItem{
+		ctx:      ctx,
+		request:  request,
+		resultCh: make(chan batchResult, 1),
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, item)
+	if len(b.pending) >= b.maxRequests {
+		b.flushLocked()
+		return item.resultCh
+	}
+	if len(b.pending) == 1 {
+		generation := b.generation
+		time.AfterFunc(b.maxWait, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if b.generation == generation {
+				b.flushLocked()
+			}
+		})
+	}
+	return item.resultCh
+}
+
+func (b *BatchMiddleware) flushLocked() {
+	items := b.pending
+	b.pending = nil
+	b.generation++
+	if len(items) == 0 {
+		return
+	}
+	go b.execute(items)
+}
+
+// execute runs the batch with the binding context, so a caller leaving early does not fail the other requests in the
+// batch. The batch carries the span and the deadline of its first request, requests of callers which already left are
+// not executed
+func (b *BatchMiddleware) execute(items []*batchItem) {
+	var requests []*types.Request
+	var executed []*batchItem
+	for _, item := range items {
+		if err := item.ctx.Err(); err != nil {
+			item.resultCh <- batchResult{err: err}
+			continue
+		}
+		requests = append(requests, item.request)
+		executed = append(executed, item)
+	}
+	if len(executed) == 0 {
+		return
+	}
+	ctx, cancel := b.batchContext(executed[0].ctx)
+	defer cancel()
+	responses, errs, err := b.target.DoBatch(ctx, requests)
+	for i, item := range executed {
+		switch {
+		case err != nil:
+			item.resultCh <- batchResult{err: err}
+		case i < len(errs) && errs[i] != nil:
+			var resp *types.Response
+			if i < len(responses) {
+				resp = responses[i]
+			}
+			item.resultCh <- batchResult{resp: resp, err: errs[i]}
+		case i >= len(responses) || responses[i] == nil:
+			item.resultCh <- batchResult{err: fmt.Errorf("no response received for batch request %d", i)}
+		default:
+			item.resultCh <- batchResult{resp: responses[i]}
+		}
+	}
+}
+
+// batchContext returns the binding context with the span and the deadline of the request context
+func (b *BatchMiddleware) batchContext(requestCtx context.Context) (context.Context, context.CancelFunc) {
+	ctx := trace.ContextWithSpan(b.ctx, trace.SpanFromContext(requestCtx))
+	if deadline, ok := requestCtx.Deadline(); ok {
+		return context.WithDeadline(ctx, deadline)
+	}
+	return context.WithCancel(ctx)
+}
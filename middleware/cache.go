@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"container/list"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheTTLSeconds = 60
+	defaultCacheMaxEntries = 1000
+	cacheInvalidateAll     = "*"
+)
+
+type CacheMiddleware struct {
+	methods           map[string]bool
+	invalidateMethods map[string]bool
+	invalidateAll     bool
+	ttl               time.Duration
+	maxEntries        int
+	maxSize           int64
+	mu                sync.Mutex
+	size              int64
+	generation        uint64
+	entries           map[string]*list.Element
+	order             *list.List
+}
+
+type cacheEntry struct {
+	key       string
+	resp      *types.Response
+	size      int64
+	expiresAt time.Time
+}
+
+func NewCacheMiddleware(meta types.Metadata) (*CacheMiddleware, error) {
+	ttl, err := meta.ParseIntWithRange("cache_ttl_seconds", defaultCacheTTLSeconds, 1, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache ttl value, %w", err)
+	}
+	maxEntries, err := meta.ParseIntWithRange("cache_max_entries", defaultCacheMaxEntries, 1, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache max entries value, %w", err)
+	}
+	maxSize, err := meta.ParseIntWithRange("cache_max_size_bytes", 0, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache max size value, %w", err)
+	}
+	c := &CacheMiddleware{
+		methods:           parseStringSet(meta.ParseString("cache_methods", "")),
+		invalidateMethods: parseStringSet(meta.ParseString("cache_invalidate_methods", "")),
+		ttl:               time.Duration(ttl) * time.Second,
+		maxEntries:        maxEntries,
+		maxSize:           int64(maxSize),
+		entries:           map[string]*list.Element{},
+		order:             list.New(),
+	}
+	if c.invalidateMethods[cacheInvalidateAll] {
+		delete(c.invalidateMethods, cacheInvalidateAll)
+		c.invalidateAll = true
+	}
+	for method := range c.invalidateMethods {
+		if c.methods[method] {
+			return nil, fmt.Errorf("invalid cache invalidate methods value, method %s is cached", method)
+		}
+	}
+	return c, nil
+}
+
+// parseStringSet parses a comma separated list of values
+func parseStringSet(value string) map[string]bool {
+	set := map[string]bool{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			set[item] = true
+		}
+	}
+	return set
+}
+
+func (c *CacheMiddleware) enabled() bool {
+	return len(c.methods) > 0
+}
+
+func (c *CacheMiddleware) cached(request *types.Request) bool {
+	return c.methods[request.Metadata.Get("method")]
+}
+
+// key returns the cache key of request, a hash of the request method, metadata and data. Transport and trace context
+// metadata are not hashed, so traced requests of the same query share cached responses
+func (c *CacheMiddleware) key(request *types.Request) string {
+	return requestHash(request)
+}
+
+// invalidates reports whether a successful request of a write method purges the cache
+func (c *CacheMiddleware) invalidates(request *types.Request) bool {
+	method := request.Metadata.Get("method")
+	return c.invalidateMethods[method] || (c.invalidateAll && !c.methods[method])
+}
+
+// get returns a copy of the cached response of key and the current cache generation
+func (c *CacheMiddleware) get(key string) (*types.Response, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, c.generation, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeLocked(el)
+		return nil, c.generation, false
+	}
+	c.order.MoveToFront(el)
+	return copyResponse(entry.resp), c.generation, true
+}
+
+// set caches resp, unless the cache was purged since generation, so a response read before a write is not cached after it
+func (c *CacheMiddleware) set(key string, resp *types.Response, generation uint64) {
+	size := int64(resp.Size())
+	if c.maxSize > 0 && size > c.maxSize {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if el, ok := c.entries[key]; ok {
+		c.removeLocked(el)
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{
+		key:       key,
+		resp:      copyResponse(resp),
+		size:      size,
+		expiresAt: time.Now().Add(c.ttl),
+	})
+	c.size += size
+	for c.order.Len() > c.maxEntries || (c.maxSize > 0 && c.size > c.maxSize) {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+func (c *CacheMiddleware) removeLocked(el *list.Element) {
+	entry := c.order.Remove(el).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
+
+// Purge removes all cached responses
+func (c *CacheMiddleware) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.size = 0
+	c.entries = map[string]*list.Element{}
+	c.order.Init()
+}
+
+// Len returns the number of cached responses
+func (c *CacheMiddleware) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func copyResponse(resp *types.Response) *types.Response {
+	cp := &types.Response{
+		Metadata: types.NewMetadata(),
+		Data:     append([]byte(nil), resp.Data...),
+		IsError:  resp.IsError,
+		Error:    resp.Error,
+	}
+	for key, value := range resp.Metadata {
+		cp.Metadata[key] = value
+	}
+	return cp
+}
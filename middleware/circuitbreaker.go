@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/config"
+	"github.com/kubemq-hub/kubemq-targets/pkg/metrics"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"math"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open, request rejected")
+
+type CircuitBreakerState string
+
+const (
+	CircuitBreakerStateDisabled CircuitBreakerState = ""
+	CircuitBreakerStateClosed   CircuitBreakerState = "closed"
+	CircuitBreakerStateHalfOpen CircuitBreakerState = "half-open"
+	CircuitBreakerStateOpen     CircuitBreakerState = "open"
+)
+
+var circuitBreakerStateValues = map[CircuitBreakerState]float64{
+	CircuitBreakerStateClosed:   0,
+	CircuitBreakerStateHalfOpen: 1,
+	CircuitBreakerStateOpen:     2,
+}
+
+type CircuitBreakerMiddleware struct {
+	sync.Mutex
+	failureThreshold int
+	openDuration     time.Duration
+	halfOpenProbes   int
+	state            CircuitBreakerState
+	failures         int
+	openedAt         time.Time
+	probesInFlight   int
+	probesSucceeded  int
+	generation       uint64
+	exporter         *metrics.Exporter
+	metricReport     *metrics.Report
+}
+
+func NewCircuitBreakerMiddleware(cfg config.BindingConfig, exporter *metrics.Exporter) (*CircuitBreakerMiddleware, error) {
+	threshold, err := cfg.Properties.ParseIntWithRange("circuit_breaker_failure_threshold", 0, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid circuit breaker failure threshold value, %w", err)
+	}
+	openDuration, err := cfg.Properties.ParseIntWithRange("circuit_breaker_open_duration_milliseconds", 10000, 1, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid circuit breaker open duration milliseconds value, %w", err)
+	}
+	probes, err := cfg.Properties.ParseIntWithRange("circuit_breaker_half_open_probes", 1, 1, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid circuit breaker half open probes value, %w", err)
+	}
+	cb := &CircuitBreakerMiddleware{
+		failureThreshold: threshold,
+		openDuration:     time.Duration(openDuration) * time.Millisecond,
+		halfOpenProbes:   probes,
+		state:            CircuitBreakerStateDisabled,
+		exporter:         exporter,
+		metricReport: &metrics.Report{
+			Key:        fmt.Sprintf("%s-%s-%s", cfg.Name, cfg.Source.Kind, cfg.Target.Kind),
+			Binding:    cfg.Name,
+			SourceKind: cfg.Source.Kind,
+			TargetKind: cfg.Target.Kind,
+		},
+	}
+	if threshold > 0 {
+		cb.setState(CircuitBreakerStateClosed)
+	}
+	return cb, nil
+}
+
+func (cb *CircuitBreakerMiddleware) State() CircuitBreakerState {
+	cb.Lock()
+	defer cb.Unlock()
+	return cb.state
+}
+
+func (cb *CircuitBreakerMiddleware) setState(state CircuitBreakerState) {
+	cb.state = state
+	cb.generation++
+	cb.failures = 0
+	cb.probesInFlight = 0
+	cb.probesSucceeded = 0
+	if state == CircuitBreakerStateOpen {
+		cb.openedAt = time.Now()
+	}
+	if cb.exporter != nil {
+		cb.exporter.ReportCircuitBreakerState(cb.metricReport, circuitBreakerStateValues[state])
+	}
+}
+
+// allow returns the state generation of an allowed request, or the time to wait before retrying a rejected request.
+// Results are counted only for the generation they were allowed in, so requests allowed while closed are not counted
+// as half-open probes
+func (cb *CircuitBreakerMiddleware) allow() (uint64, time.Duration, bool) {
+	cb.Lock()
+	defer cb.Unlock()
+	switch cb.state {
+	case CircuitBreakerStateOpen:
+		if elapsed := time.Since(cb.openedAt); elapsed < cb.openDuration {
+			return 0, cb.openDuration - elapsed, false
+		}
+		cb.setState(CircuitBreakerStateHalfOpen)
+		cb.probesInFlight++
+		return cb.generation, 0, true
+	case CircuitBreakerStateHalfOpen:
+		if cb.probesInFlight+cb.probesSucceeded >= cb.halfOpenProbes {
+			return 0, cb.openDuration, false
+		}
+		cb.probesInFlight++
+		return cb.generation, 0, true
+	default:
+		return cb.generation, 0, true
+	}
+}
+
+// isCircuitFailure reports whether a target error counts as a failure of the target. Invalid, not found and conflicting
+// requests and canceled requests are caused by the requests, so they are not counted
+func isCircuitFailure(err error) bool {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return false
+	}
+	switch errorClass(err) {
+	case ErrorClassTimeout, ErrorClassTarget, string(types.ErrorClassTransient), string(types.ErrorClassThrottled):
+		return true
+	default:
+		return false
+	}
+}
+
+func (cb *CircuitBreakerMiddleware) onResult(generation uint64, err error) {
+	cb.Lock()
+	defer cb.Unlock()
+	if generation != cb.generation {
+		return
+	}
+	failed := err != nil && isCircuitFailure(err)
+	switch cb.state {
+	case CircuitBreakerStateClosed:
+		if err == nil {
+			cb.failures = 0
+			return
+		}
+		if !failed {
+			return
+		}
+		cb.failures++
+		if cb.failures >= cb.failureThreshold {
+			cb.setState(CircuitBreakerStateOpen)
+		}
+	case CircuitBreakerStateHalfOpen:
+		if failed {
+			cb.setState(CircuitBreakerStateOpen)
+			return
+		}
+		cb.probesInFlight--
+		if err != nil {
+			// the probe did not test the target, so another request is allowed to probe it
+			return
+		}
+		cb.probesSucceeded++
+		if cb.probesSucceeded >= cb.halfOpenProbes {
+			cb.setState(CircuitBreakerStateClosed)
+		}
+	}
+}
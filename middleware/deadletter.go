@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/config"
+	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"github.com/kubemq-io/kubemq-go"
+	"time"
+)
+
+const (
+	DeadLetterKindQueue       = "queue"
+	DeadLetterKindEventsStore = "events-store"
+)
+
+var deadLetterKindMap = map[string]string{
+	"queue":        DeadLetterKindQueue,
+	"events-store": DeadLetterKindEventsStore,
+	"":             DeadLetterKindQueue,
+}
+
+// redeliveringSourceKinds are the source kinds which get circuit breaker rejections back, queue messages are redelivered
+// and command and query senders receive the error
+var redeliveringSourceKinds = map[string]bool{
+	"source.queue":   true,
+	"kubemq.queue":   true,
+	"source.command": true,
+	"kubemq.command": true,
+	"source.query":   true,
+	"kubemq.query":   true,
+}
+
+type DeadLetterMessage struct {
+	Binding    string         `json:"binding"`
+	SourceKind string         `json:"source_kind"`
+	TargetKind string         `json:"target_kind"`
+	Request    *types.Request `json:"request"`
+	Error      string         `json:"error"`
+	Attempts   int            `json:"attempts"`
+	ReceivedAt time.Time      `json:"received_at"`
+	FailedAt   time.Time      `json:"failed_at"`
+}
+
+type deadLetterSender interface {
+	Send(ctx context.Context, channel string, body []byte) error
+	Close() error
+}
+
+type DeadLetterMiddleware struct {
+	binding    string
+	sourceKind string
+	targetKind string
+	channel    string
+	sender     deadLetterSender
+	log        *logger.Logger
+}
+
+func NewDeadLetterMiddleware(ctx context.Context, cfg config.BindingConfig, log *logger.Logger) (*DeadLetterMiddleware, error) {
+	dl := &DeadLetterMiddleware{
+		binding:    cfg.Name,
+		sourceKind: cfg.Source.Kind,
+		targetKind: cfg.Target.Kind,
+		channel:    cfg.Properties.ParseString("dead_letter_channel", ""),
+		log:        log,
+	}
+	if dl.channel == "" {
+		return dl, nil
+	}
+	kind, err := cfg.Properties.ParseStringMap("dead_letter_kind", deadLetterKindMap)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dead letter kind value, %w", err)
+	}
+	sourceProperties, err := secrets.Resolve(cfg.Source.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving source secrets, %w", err)
+	}
+	host, port, err := cfg.Properties.MustParseAddress("dead_letter_address", sourceProperties.ParseString("address", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid dead letter address value, %w", err)
+	}
+	client, err := kubemq.NewClient(ctx,
+		kubemq.WithAddress(host, port),
+		kubemq.WithClientId(cfg.Properties.ParseString("dead_letter_client_id", fmt.Sprintf("%s-dead-letter", cfg.Name))),
+		kubemq.WithTransportType(kubemq.TransportTypeGRPC),
+		kubemq.WithAuthToken(cfg.Properties.ParseString("dead_letter_auth_token", sourceProperties.ParseString("auth_token", ""))),
+		kubemq.WithAutoReconnect(true))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting dead letter client, %w", err)
+	}
+	switch kind {
+	case DeadLetterKindEventsStore:
+		dl.sender = &eventsStoreSender{client: client}
+	default:
+		dl.sender = &queueSender{client: client}
+	}
+	return dl, nil
+}
+
+// deadLetters reports whether a failed request is sent to the dead letter channel. Circuit breaker rejections are sent
+// only for sources which cannot redeliver them, such as events and events store sources
+func (dl *DeadLetterMiddleware) deadLetters(err error) bool {
+	if errors.Is(lastError(err), ErrCircuitOpen) {
+		return !redeliveringSourceKinds[dl.sourceKind]
+	}
+	return true
+}
+
+// send sends a failed request to the dead letter channel, attempts are the target executions of the request, including
+// the retries reported by the retry middleware
+func (dl *DeadLetterMiddleware) send(ctx context.Context, request *types.Request, doErr error, receivedAt time.Time, attempts int) error {
+	msg := &DeadLetterMessage{
+		Binding:    dl.binding,
+		SourceKind: dl.sourceKind,
+		TargetKind: dl.targetKind,
+		Request:    request,
+		Error:      doErr.Error(),
+		Attempts:   attempts,
+		ReceivedAt: receivedAt,
+		FailedAt:   time.Now(),
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return dl.sender.Send(ctx, dl.channel, data)
+}
+
+func (dl *DeadLetterMiddleware) Close() error {
+	if dl.sender == nil {
+		return nil
+	}
+	return dl.sender.Close()
+}
+
+type queueSender struct {
+	client *kubemq.Client
+}
+
+func (q *queueSender) Send(ctx context.Context, channel string, body []byte) error {
+	result, err := q.client.NewQueueMessage().SetChannel(channel).SetBody(body).Send(ctx)
+	if err != nil {
+		return err
+	}
+	if result.IsError {
+		return errors.New(result.Error)
+	}
+	return nil
+}
+
+func (q *queueSender) Close() error {
+	return q.client.Close()
+}
+
+type eventsStoreSender struct {
+	client *kubemq.Client
+}
+
+func (e *eventsStoreSender) Send(ctx context.Context, channel string, body []byte) error {
+	result, err := e.client.ES().SetChannel(channel).SetBody(body).Send(ctx)
+	if err != nil {
+		return err
+	}
+	if !result.Sent {
+		if result.Err != nil {
+			return result.Err
+		}
+		return fmt.Errorf("dead letter message was not sent")
+	}
+	return nil
+}
+
+func (e *eventsStoreSender) Close() error {
+	return e.client.Close()
+}
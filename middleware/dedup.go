@@ -0,0 +1,263 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/pkg/tracing"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DedupStoreMemory = "memory"
+	DedupStoreTarget = "target"
+)
+
+// transportMetadataPrefix is the prefix of metadata keys set by kubemq sources, such as queue message attributes
+const transportMetadataPrefix = "kubemq_"
+
+var dedupStoreMap = map[string]string{
+	"memory": DedupStoreMemory,
+	"target": DedupStoreTarget,
+	"":       DedupStoreMemory,
+}
+
+// DedupStore keeps responses of processed requests by dedup key
+type DedupStore interface {
+	Get(ctx context.Context, key string) (*types.Response, bool, error)
+	Set(ctx context.Context, key string, resp *types.Response, ttl time.Duration) error
+}
+
+type dedupCall struct {
+	done chan struct{}
+	resp *types.Response
+	err  error
+}
+
+type DedupMiddleware struct {
+	ttl         time.Duration
+	keyMetadata string
+	store       DedupStore
+	mu          sync.Mutex
+	inFlight    map[string]*dedupCall
+}
+
+// NewDedupMiddleware creates a dedup middleware, when store is nil an in-memory LRU store is used
+func NewDedupMiddleware(meta types.Metadata, store DedupStore) (*DedupMiddleware, error) {
+	ttl, err := meta.ParseIntWithRange("dedup_ttl_seconds", 0, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dedup ttl value, %w", err)
+	}
+	maxEntries, err := meta.ParseIntWithRange("dedup_max_entries", 10000, 1, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dedup max entries value, %w", err)
+	}
+	if _, err := DedupStoreKind(meta); err != nil {
+		return nil, err
+	}
+	d := &DedupMiddleware{
+		ttl:         time.Duration(ttl) * time.Second,
+		keyMetadata: meta.ParseString("dedup_key", ""),
+		store:       store,
+		inFlight:    map[string]*dedupCall{},
+	}
+	if d.store == nil {
+		d.store = NewMemoryDedupStore(maxEntries)
+	}
+	return d, nil
+}
+
+// DedupStoreKind returns the configured dedup store kind
+func DedupStoreKind(meta types.Metadata) (string, error) {
+	kind, err := meta.ParseStringMap("dedup_store", dedupStoreMap)
+	if err != nil {
+		return "", fmt.Errorf("invalid dedup store value, %w", err)
+	}
+	return kind, nil
+}
+
+func (d *DedupMiddleware) enabled() bool {
+	return d.ttl > 0
+}
+
+func (d *DedupMiddleware) key(request *types.Request) string {
+	if request == nil {
+		return ""
+	}
+	if d.keyMetadata != "" {
+		return request.Metadata.Get(d.keyMetadata)
+	}
+	return requestHash(request)
+}
+
+// begin returns the in-flight call of the key and true when the caller should process the request
+func (d *DedupMiddleware) begin(key string) (*dedupCall, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if call, ok := d.inFlight[key]; ok {
+		return call, false
+	}
+	call := &dedupCall{done: make(chan struct{})}
+	d.inFlight[key] = call
+	return call, true
+}
+
+func (d *DedupMiddleware) end(key string, call *dedupCall) {
+	d.mu.Lock()
+	delete(d.inFlight, key)
+	d.mu.Unlock()
+	close(call.done)
+}
+
+// requestHash returns a sha256 hash of the request metadata, sorted by keys, and data. Transport metadata keys are not
+// hashed, as they differ between deliveries of the same request
+func requestHash(request *types.Request) string {
+	keys := make([]string, 0, len(request.Metadata))
+	for key := range request.Metadata {
+		if isTransportMetadata(key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(h, "%d:%s%d:%s", len(key), key, len(request.Metadata[key]), request.Metadata[key])
+	}
+	_, _ = h.Write(request.Data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isTransportMetadata returns true for the trace context and kubemq source metadata keys
+func isTransportMetadata(key string) bool {
+	return strings.HasPrefix(key, transportMetadataPrefix) || tracing.IsPropagationKey(key)
+}
+
+type memoryDedupEntry struct {
+	key       string
+	resp      *types.Response
+	expiresAt time.Time
+}
+
+// MemoryDedupStore is an in-memory LRU dedup store
+type MemoryDedupStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+func NewMemoryDedupStore(maxEntries int) *MemoryDedupStore {
+	return &MemoryDedupStore{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+func (m *MemoryDedupStore) Get(ctx context.Context, key string) (*types.Response, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*memoryDedupEntry)
+	if time.Now().After(entry.expiresAt) {
+		m.order.Remove(el)
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	m.order.MoveToFront(el)
+	return copyResponse(entry.resp), true, nil
+}
+
+func (m *MemoryDedupStore) Set(ctx context.Context, key string, resp *types.Response, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := &memoryDedupEntry{
+		key:       key,
+		resp:      copyResponse(resp),
+		expiresAt: time.Now().Add(ttl),
+	}
+	if el, ok := m.entries[key]; ok {
+		el.Value = entry
+		m.order.MoveToFront(el)
+		return nil
+	}
+	m.entries[key] = m.order.PushFront(entry)
+	for m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryDedupEntry).key)
+	}
+	return nil
+}
+
+type targetDedupEntry struct {
+	Response  *types.Response `json:"response"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// TargetDedupStore keeps dedup entries in a cache target supporting get and set methods and ttl_seconds expiry, such as
+// cache.redis
+type TargetDedupStore struct {
+	prefix string
+	target Middleware
+}
+
+func NewTargetDedupStore(prefix string, target Middleware) *TargetDedupStore {
+	return &TargetDedupStore{
+		prefix: prefix,
+		target: target,
+	}
+}
+
+func (t *TargetDedupStore) Get(ctx context.Context, key string) (*types.Response, bool, error) {
+	resp, err := t.target.Do(ctx, types.NewRequest().
+		SetMetadataKeyValue("method", "get").
+		SetMetadataKeyValue("key", t.prefix+key))
+	if err != nil || resp == nil || resp.IsError || len(resp.Data) == 0 {
+		// cache targets report a missing key as an error
+		return nil, false, nil
+	}
+	entry := &targetDedupEntry{}
+	if err := json.Unmarshal(resp.Data, entry); err != nil {
+		return nil, false, fmt.Errorf("invalid dedup entry, %w", err)
+	}
+	if entry.Response == nil || time.Now().After(entry.ExpiresAt) {
+		return nil, false, nil
+	}
+	return entry.Response, true, nil
+}
+
+func (t *TargetDedupStore) Set(ctx context.Context, key string, resp *types.Response, ttl time.Duration) error {
+	data, err := json.Marshal(&targetDedupEntry{
+		Response:  resp,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return err
+	}
+	result, err := t.target.Do(ctx, types.NewRequest().
+		SetMetadataKeyValue("method", "set").
+		SetMetadataKeyValue("key", t.prefix+key).
+		SetMetadataKeyValue("ttl_seconds", strconv.Itoa(int(math.Ceil(ttl.Seconds())))).
+		SetData(data))
+	if err != nil {
+		return err
+	}
+	if result != nil && result.IsError {
+		return fmt.Errorf("%s", result.Error)
+	}
+	return nil
+}
@@ -1,9 +1,22 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/kubemq-hub/kubemq-targets/config"
 	"github.com/kubemq-hub/kubemq-targets/pkg/metrics"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"strings"
+)
+
+const (
+	ErrorClassTimeout     = "timeout"
+	ErrorClassCircuitOpen = "circuit_open"
+	ErrorClassCanceled    = "canceled"
+	ErrorClassTarget      = "target"
 )
 
 type MetricsMiddleware struct {
@@ -11,10 +24,32 @@ type MetricsMiddleware struct {
 	metricReport *metrics.Report
 }
 
+func parseLatencyBuckets(value string) ([]float64, error) {
+	if value == "" {
+		return prometheus.DefBuckets, nil
+	}
+	var buckets []float64
+	for _, item := range strings.Split(value, ",") {
+		bucket, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket value %s", item)
+		}
+		if len(buckets) > 0 && bucket <= buckets[len(buckets)-1] {
+			return nil, fmt.Errorf("buckets must be in increasing order")
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
 func NewMetricsMiddleware(cfg config.BindingConfig, exporter *metrics.Exporter) (*MetricsMiddleware, error) {
 	if exporter == nil {
 		return nil, fmt.Errorf("no valid exporter found")
 	}
+	buckets, err := parseLatencyBuckets(cfg.Properties.ParseString("metrics_latency_buckets", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid metrics latency buckets value, %w", err)
+	}
 	m := &MetricsMiddleware{
 		exporter: exporter,
 		metricReport: &metrics.Report{
@@ -27,15 +62,36 @@ func NewMetricsMiddleware(cfg config.BindingConfig, exporter *metrics.Exporter)
 			ResponseCount:  0,
 			ResponseVolume: 0,
 			ErrorsCount:    0,
+			TimeoutsCount:  0,
 		},
 	}
+	if err := exporter.RegisterLatency(m.metricReport, buckets); err != nil {
+		return nil, fmt.Errorf("error registering latency metrics, %w", err)
+	}
 	return m, nil
 }
 
-func (m *MetricsMiddleware) clearReport() {
-	m.metricReport.ErrorsCount = 0
-	m.metricReport.ResponseVolume = 0
-	m.metricReport.ResponseCount = 0
-	m.metricReport.RequestVolume = 0
-	m.metricReport.RequestCount = 0
+func (m *MetricsMiddleware) newReport() *metrics.Report {
+	return &metrics.Report{
+		Key:        m.metricReport.Key,
+		Binding:    m.metricReport.Binding,
+		SourceKind: m.metricReport.SourceKind,
+		TargetKind: m.metricReport.TargetKind,
+	}
+}
+
+func errorClass(err error) string {
+	err = lastError(err)
+	switch {
+	case IsTimeoutError(err):
+		return ErrorClassTimeout
+	case errors.Is(err, ErrCircuitOpen):
+		return ErrorClassCircuitOpen
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case types.ErrorClassOf(err) != types.ErrorClassUnknown:
+		return string(types.ErrorClassOf(err))
+	default:
+		return ErrorClassTarget
+	}
 }
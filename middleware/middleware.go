@@ -2,8 +2,11 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"github.com/kubemq-hub/kubemq-targets/pkg/retry"
 	"github.com/kubemq-hub/kubemq-targets/types"
+	"sync/atomic"
+	"time"
 )
 
 type Middleware interface {
@@ -67,8 +70,15 @@ func Log(log *LogMiddleware) MiddlewareFunc {
 func RateLimiter(rl *RateLimitMiddleware) MiddlewareFunc {
 	return func(df Middleware) Middleware {
 		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
-			rl.Take()
-			return df.Do(ctx, request)
+			wait, err := rl.wait(ctx)
+			requestStatsFrom(ctx).addRateLimitWait(wait)
+			if err != nil {
+				return nil, err
+			}
+			defer rl.release()
+			resp, err := df.Do(ctx, request)
+			rl.onResult(resp, err)
+			return resp, err
 		})
 	}
 }
@@ -77,35 +87,287 @@ func Retry(r *RetryMiddleware) MiddlewareFunc {
 	return func(df Middleware) Middleware {
 		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
 			var resp *types.Response
+			attempts := int64(0)
+			defer func() {
+				if attempts > 1 {
+					requestStatsFrom(ctx).addRetries(attempts - 1)
+				}
+			}()
+			var nonRetryable error
+			opts := append(r.opts[:len(r.opts):len(r.opts)], retry.Context(ctx), retry.RetryIf(func(err error) bool {
+				if ctx.Err() != nil || !r.retryable(err) {
+					nonRetryable = err
+					return false
+				}
+				return true
+			}))
 			err := retry.Do(func() error {
+				attempts++
 				var doErr error
 				resp, doErr = df.Do(ctx, request)
 				if doErr != nil {
 					return doErr
 				}
 				return nil
-			}, r.opts...)
+			}, opts...)
+			if nonRetryable != nil {
+				return resp, nonRetryable
+			}
+			return resp, err
+		})
+	}
+}
+func Timeout(tm *TimeoutMiddleware) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			timeout, err := tm.requestTimeout(request)
+			if err != nil {
+				return nil, retry.Unrecoverable(err)
+			}
+			if timeout == 0 {
+				return df.Do(ctx, request)
+			}
+			if err := tm.acquire(); err != nil {
+				return nil, err
+			}
+			timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			type result struct {
+				resp *types.Response
+				err  error
+			}
+			resultCh := make(chan result, 1)
+			// state is 0 while the target executes, 1 when it returned and 2 when the timeout returned first
+			state := int32(0)
+			go func() {
+				resp, err := df.Do(timeoutCtx, request)
+				resultCh <- result{resp: resp, err: err}
+				if !atomic.CompareAndSwapInt32(&state, 0, 1) {
+					atomic.AddInt64(&tm.pending, -1)
+				}
+			}()
+			select {
+			case r := <-resultCh:
+				return r.resp, r.err
+			case <-timeoutCtx.Done():
+				atomic.AddInt64(&tm.pending, 1)
+				if !atomic.CompareAndSwapInt32(&state, 0, 2) {
+					atomic.AddInt64(&tm.pending, -1)
+					r := <-resultCh
+					return r.resp, r.err
+				}
+				if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+					return nil, &TimeoutError{Timeout: timeout}
+				}
+				return nil, timeoutCtx.Err()
+			}
+		})
+	}
+}
+func Batch(b *BatchMiddleware) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			if !b.enabled() {
+				return df.Do(ctx, request)
+			}
+			select {
+			case r := <-b.add(ctx, request):
+				return r.resp, r.err
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		})
+	}
+}
+func Recorder(r *RecorderMiddleware) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			if !r.enabled() || !r.sampled() {
+				return df.Do(ctx, request)
+			}
+			start := time.Now()
+			resp, err := df.Do(ctx, request)
+			r.record(start, request, resp, err)
+			return resp, err
+		})
+	}
+}
+func Transform(tm *TransformMiddleware) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			transformedRequest, err := tm.transformRequest(request)
+			if err != nil {
+				return nil, retry.Unrecoverable(err)
+			}
+			resp, err := df.Do(ctx, transformedRequest)
+			if err != nil {
+				return resp, err
+			}
+			transformedResponse, err := tm.transformResponse(resp)
+			if err != nil {
+				return nil, retry.Unrecoverable(err)
+			}
+			return transformedResponse, nil
+		})
+	}
+}
+func CircuitBreaker(cb *CircuitBreakerMiddleware) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			generation, retryAfter, ok := cb.allow()
+			if !ok {
+				return nil, types.NewThrottledError(ErrCircuitOpen, retryAfter)
+			}
+			resp, err := df.Do(ctx, request)
+			cb.onResult(generation, err)
+			return resp, err
+		})
+	}
+}
+func Cache(c *CacheMiddleware) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			if !c.enabled() || request == nil {
+				return df.Do(ctx, request)
+			}
+			if !c.cached(request) {
+				resp, err := df.Do(ctx, request)
+				if err == nil && resp != nil && !resp.IsError && c.invalidates(request) {
+					c.Purge()
+				}
+				return resp, err
+			}
+			key := c.key(request)
+			stats := requestStatsFrom(ctx)
+			cached, generation, ok := c.get(key)
+			if ok {
+				stats.addCacheHit()
+				return cached, nil
+			}
+			stats.addCacheMiss()
+			resp, err := df.Do(ctx, request)
+			if err == nil && resp != nil && !resp.IsError {
+				c.set(key, resp, generation)
+			}
 			return resp, err
 		})
 	}
 }
+func Dedup(d *DedupMiddleware) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			if !d.enabled() {
+				return df.Do(ctx, request)
+			}
+			key := d.key(request)
+			if key == "" {
+				return df.Do(ctx, request)
+			}
+			call, first := d.begin(key)
+			if !first {
+				select {
+				case <-call.done:
+					if call.resp == nil {
+						return nil, call.err
+					}
+					return copyResponse(call.resp), call.err
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+			defer d.end(key, call)
+			if resp, ok, err := d.store.Get(ctx, key); err == nil && ok {
+				call.resp = copyResponse(resp)
+				return resp, nil
+			}
+			resp, err := df.Do(ctx, request)
+			call.err = err
+			if resp != nil {
+				call.resp = copyResponse(resp)
+			}
+			if err == nil && resp != nil && !resp.IsError {
+				_ = d.store.Set(ctx, key, call.resp, d.ttl)
+			}
+			return resp, err
+		})
+	}
+}
+func Validation(v *ValidationMiddleware) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			if !v.enabled() || request == nil {
+				return df.Do(ctx, request)
+			}
+			if err := v.validate(request); err != nil {
+				return nil, err
+			}
+			return df.Do(ctx, request)
+		})
+	}
+}
+func DeadLetter(dl *DeadLetterMiddleware) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			receivedAt := time.Now()
+			stats := requestStatsFrom(ctx)
+			if stats == nil {
+				ctx, stats = withRequestStats(ctx)
+			}
+			resp, err := df.Do(ctx, request)
+			if err == nil || dl.sender == nil || !dl.deadLetters(err) {
+				return resp, err
+			}
+			sendErr := dl.send(ctx, request, err, receivedAt, int(stats.getRetries())+1)
+			if sendErr != nil {
+				if dl.log != nil {
+					dl.log.Errorf("error sending request to dead letter channel %s, %s", dl.channel, sendErr.Error())
+				}
+				return resp, err
+			}
+			return types.NewResponse().
+				SetMetadataKeyValue("dead_letter_channel", dl.channel).
+				SetError(err), nil
+		})
+	}
+}
 func Metric(m *MetricsMiddleware) MiddlewareFunc {
 	return func(df Middleware) Middleware {
 		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			start := m.newReport()
+			start.InFlight = 1
+			m.exporter.Report(start)
+			ctx, stats := withRequestStats(ctx)
+			startTime := time.Now()
 			resp, err := df.Do(ctx, request)
-			m.clearReport()
+			latency := time.Since(startTime).Seconds()
+			report := m.newReport()
+			report.InFlight = -1
+			report.LatencySecondsSum = latency
+			report.LatencyAvgSeconds = latency
+			report.LatencyMaxSeconds = latency
+			report.RetriesCount = float64(stats.getRetries())
+			report.RateLimitWaitSeconds = stats.getRateLimitWait().Seconds()
+			report.CacheHits = float64(stats.getCacheHits())
+			report.CacheMisses = float64(stats.getCacheMisses())
+			method := ""
 			if request != nil {
-				m.metricReport.RequestVolume = request.Size()
-				m.metricReport.RequestCount = 1
+				report.RequestVolume = request.Size()
+				report.RequestCount = 1
+				method = request.Metadata.Get("method")
 			}
 			if resp != nil {
-				m.metricReport.ResponseVolume = resp.Size()
-				m.metricReport.ResponseCount = 1
+				report.ResponseVolume = resp.Size()
+				report.ResponseCount = 1
 			}
 			if err != nil {
-				m.metricReport.ErrorsCount = 1
+				report.ErrorsCount = 1
+				if IsTimeoutError(err) {
+					report.TimeoutsCount = 1
+				}
+				report.ErrorClasses = map[string]float64{errorClass(err): 1}
 			}
-			m.exporter.Report(m.metricReport)
+			m.exporter.Report(report)
+			m.exporter.ObserveLatency(report, method, latency)
 			return resp, err
 		})
 	}
@@ -2,13 +2,28 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/kubemq-hub/builder/connector/common"
 	"github.com/kubemq-hub/kubemq-targets/config"
 	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
 	"github.com/kubemq-hub/kubemq-targets/pkg/metrics"
+	"github.com/kubemq-hub/kubemq-targets/pkg/tracing"
 	"github.com/kubemq-hub/kubemq-targets/types"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
 	"math"
+	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -17,6 +32,8 @@ type mockTarget struct {
 	request  *types.Request
 	response *types.Response
 	err      error
+	// errs are returned by order of executions instead of err, the last error is returned by later executions
+	errs     []error
 	delay    time.Duration
 	executed int
 }
@@ -28,6 +45,12 @@ func (m *mockTarget) Init(ctx context.Context, cfg config.Spec) error {
 func (m *mockTarget) Do(ctx context.Context, request *types.Request) (*types.Response, error) {
 	time.Sleep(m.delay)
 	m.executed++
+	if len(m.errs) > 0 {
+		if m.executed > len(m.errs) {
+			return m.response, m.errs[len(m.errs)-1]
+		}
+		return m.response, m.errs[m.executed-1]
+	}
 	return m.response, m.err
 }
 
@@ -90,6 +113,59 @@ func TestClient_RateLimiter(t *testing.T) {
 			wantMaxExecution: 0,
 			wantErr:          true,
 		},
+		{
+			name: "100 per seconds with burst",
+			mock: &mockTarget{
+				request:  types.NewRequest(),
+				response: types.NewResponse(),
+			},
+			meta: map[string]string{
+				"rate_per_seconds": "100",
+				"rate_burst":       "50",
+			},
+			timeToRun:        time.Second,
+			wantMaxExecution: 160,
+			wantErr:          false,
+		},
+		{
+			name: "bad burst",
+			mock: &mockTarget{
+				request:  types.NewRequest(),
+				response: types.NewResponse(),
+			},
+			meta: map[string]string{
+				"rate_per_seconds": "100",
+				"rate_burst":       "0",
+			},
+			timeToRun: time.Second,
+			wantErr:   true,
+		},
+		{
+			name: "adaptive without rate",
+			mock: &mockTarget{
+				request:  types.NewRequest(),
+				response: types.NewResponse(),
+			},
+			meta: map[string]string{
+				"rate_adaptive": "true",
+			},
+			timeToRun: time.Second,
+			wantErr:   true,
+		},
+		{
+			name: "bad adaptive min rate",
+			mock: &mockTarget{
+				request:  types.NewRequest(),
+				response: types.NewResponse(),
+			},
+			meta: map[string]string{
+				"rate_per_seconds":              "10",
+				"rate_adaptive":                 "true",
+				"rate_adaptive_min_per_seconds": "20",
+			},
+			timeToRun: time.Second,
+			wantErr:   true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -119,6 +195,200 @@ func TestClient_RateLimiter(t *testing.T) {
 	}
 }
 
+func TestClient_RateLimiterContext(t *testing.T) {
+	rl, err := NewRateLimitMiddleware(types.Metadata{"rate_per_seconds": "1"})
+	require.NoError(t, err)
+	mock := &mockTarget{response: types.NewResponse()}
+	md := Chain(mock, RateLimiter(rl))
+	_, err = md.Do(context.Background(), types.NewRequest())
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = md.Do(ctx, types.NewRequest())
+	require.Error(t, err)
+	require.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+	require.Equal(t, 1, mock.executed)
+}
+
+func TestClient_RateLimiterMaxInFlight(t *testing.T) {
+	rl, err := NewRateLimitMiddleware(types.Metadata{"rate_max_in_flight": "2"})
+	require.NoError(t, err)
+	running := int32(0)
+	maxRunning := int32(0)
+	target := DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		return types.NewResponse(), nil
+	})
+	md := Chain(target, RateLimiter(rl))
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := md.Do(context.Background(), types.NewRequest())
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	blocked := make(chan struct{})
+	blocking := DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+		<-blocked
+		return types.NewResponse(), nil
+	})
+	md = Chain(blocking, RateLimiter(rl))
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, _ = md.Do(context.Background(), types.NewRequest())
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	_, err = md.Do(ctx, types.NewRequest())
+	require.Equal(t, context.Canceled, err)
+	close(blocked)
+}
+
+func TestClient_RateLimiterAdaptive(t *testing.T) {
+	rl, err := NewRateLimitMiddleware(types.Metadata{
+		"rate_per_seconds":               "100",
+		"rate_burst":                     "100",
+		"rate_adaptive":                  "true",
+		"rate_adaptive_min_per_seconds":  "10",
+		"rate_adaptive_recovery_seconds": "1",
+	})
+	require.NoError(t, err)
+	throttled := int32(1)
+	target := DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+		if atomic.LoadInt32(&throttled) == 1 {
+			return nil, &mockCodeError{code: "ThrottlingException"}
+		}
+		return types.NewResponse(), nil
+	})
+	md := Chain(target, RateLimiter(rl))
+	ctx := context.Background()
+	_, err = md.Do(ctx, types.NewRequest())
+	require.Error(t, err)
+	require.Equal(t, float64(50), rl.Rate())
+	_, _ = md.Do(ctx, types.NewRequest())
+	require.Equal(t, float64(50), rl.Rate())
+	rl.lastBackoff = time.Time{}
+	_, _ = md.Do(ctx, types.NewRequest())
+	require.Equal(t, float64(25), rl.Rate())
+
+	atomic.StoreInt32(&throttled, 0)
+	time.Sleep(500 * time.Millisecond)
+	_, err = md.Do(ctx, types.NewRequest())
+	require.NoError(t, err)
+	require.Greater(t, rl.Rate(), float64(25))
+	require.Less(t, rl.Rate(), float64(100))
+	time.Sleep(time.Second)
+	_, err = md.Do(ctx, types.NewRequest())
+	require.NoError(t, err)
+	require.Equal(t, float64(100), rl.Rate())
+}
+
+func TestIsThrottlingError(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *types.Response
+		err  error
+		want bool
+	}{
+		{
+			name: "no error",
+			resp: types.NewResponse().SetMetadataKeyValue("code", "200"),
+			want: false,
+		},
+		{
+			name: "http 429 response",
+			resp: types.NewResponse().SetMetadataKeyValue("code", "429"),
+			want: true,
+		},
+		{
+			name: "http 429 response with error",
+			resp: types.NewResponse().SetMetadataKeyValue("code", "429"),
+			err:  fmt.Errorf("some-error"),
+			want: true,
+		},
+		{
+			name: "throttled error",
+			err:  types.NewThrottledError(fmt.Errorf("some-error"), 0),
+			want: true,
+		},
+		{
+			name: "error with code",
+			err:  fmt.Errorf("put failed, %w", &mockCodeError{code: "ProvisionedThroughputExceededException"}),
+			want: true,
+		},
+		{
+			name: "error with other code",
+			err:  &mockCodeError{code: "ResourceNotFoundException"},
+			want: false,
+		},
+		{
+			name: "error with status code",
+			err:  fmt.Errorf("put failed, %w", &mockStatusCodeError{statusCode: 429}),
+			want: true,
+		},
+		{
+			name: "grpc resource exhausted error",
+			err:  fmt.Errorf("publish failed, %w", status.Error(grpccodes.ResourceExhausted, "quota exceeded")),
+			want: true,
+		},
+		{
+			name: "message with throttling text",
+			err:  fmt.Errorf("ThrottlingException: Rate exceeded"),
+			want: false,
+		},
+		{
+			name: "message with 429",
+			err:  fmt.Errorf("error inserting order 10429 into throttling_log, duplicate key"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, IsThrottlingError(tt.resp, tt.err))
+		})
+	}
+}
+
+type mockStatusCodeError struct {
+	statusCode int
+}
+
+func (e *mockStatusCodeError) Error() string {
+	return "request failed"
+}
+
+func (e *mockStatusCodeError) StatusCode() int {
+	return e.statusCode
+}
+
+type mockCodeError struct {
+	code string
+}
+
+func (e *mockCodeError) Error() string {
+	return "request failed"
+}
+
+func (e *mockCodeError) Code() string {
+	return e.code
+}
+
 func TestClient_Retry(t *testing.T) {
 	log := logger.NewLogger("TestClient_Retry")
 	tests := []struct {
@@ -322,6 +592,109 @@ func TestClient_Retry(t *testing.T) {
 		})
 	}
 }
+func TestClient_RetryErrorClasses(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		meta         types.Metadata
+		wantExecuted int
+		wantMinTime  time.Duration
+		wantMaxTime  time.Duration
+		wantErr      bool
+	}{
+		{
+			name:         "invalid request not retried",
+			err:          types.NewInvalidRequestError(fmt.Errorf("invalid metadata")),
+			meta:         map[string]string{"retry_attempts": "3"},
+			wantExecuted: 1,
+			wantMaxTime:  50 * time.Millisecond,
+		},
+		{
+			name:         "transient retried",
+			err:          types.NewTransientError(fmt.Errorf("connection reset")),
+			meta:         map[string]string{"retry_attempts": "3", "retry_delay_type": "fixed", "retry_delay_milliseconds": "10"},
+			wantExecuted: 3,
+		},
+		{
+			name:         "unknown not retried when not listed",
+			err:          fmt.Errorf("some-error"),
+			meta:         map[string]string{"retry_attempts": "3", "retry_error_classes": "transient"},
+			wantExecuted: 1,
+		},
+		{
+			name:         "not found retried when listed",
+			err:          types.NewNotFoundError(fmt.Errorf("not found")),
+			meta:         map[string]string{"retry_attempts": "3", "retry_error_classes": "not_found", "retry_delay_type": "fixed", "retry_delay_milliseconds": "10"},
+			wantExecuted: 3,
+		},
+		{
+			name:         "throttled provider code retried",
+			err:          fmt.Errorf("put failed, %w", &mockCodeError{code: "ThrottlingException"}),
+			meta:         map[string]string{"retry_attempts": "2", "retry_error_classes": "throttled", "retry_delay_type": "fixed", "retry_delay_milliseconds": "10"},
+			wantExecuted: 2,
+		},
+		{
+			name:         "per class delay",
+			err:          types.NewTransientError(fmt.Errorf("connection reset")),
+			meta:         map[string]string{"retry_attempts": "3", "retry_delay_type": "fixed", "retry_delay_milliseconds": "1000", "retry_transient_delay_milliseconds": "10"},
+			wantExecuted: 3,
+			wantMaxTime:  500 * time.Millisecond,
+		},
+		{
+			name:         "retry after honored",
+			err:          types.NewThrottledError(fmt.Errorf("throttled"), 200*time.Millisecond),
+			meta:         map[string]string{"retry_attempts": "2", "retry_delay_type": "fixed", "retry_delay_milliseconds": "10"},
+			wantExecuted: 2,
+			wantMinTime:  200 * time.Millisecond,
+		},
+		{
+			name:         "retry after capped",
+			err:          types.NewThrottledError(fmt.Errorf("throttled"), 10*time.Second),
+			meta:         map[string]string{"retry_attempts": "2", "retry_delay_type": "fixed", "retry_delay_milliseconds": "10", "retry_after_max_milliseconds": "100"},
+			wantExecuted: 2,
+			wantMinTime:  100 * time.Millisecond,
+			wantMaxTime:  time.Second,
+		},
+		{
+			name:    "bad error class",
+			meta:    map[string]string{"retry_error_classes": "transient,bad-class"},
+			wantErr: true,
+		},
+		{
+			name:    "bad class delay",
+			meta:    map[string]string{"retry_throttled_delay_milliseconds": "-1"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			r, err := NewRetryMiddleware(tt.meta, nil)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			mock := &mockTarget{err: tt.err}
+			md := Chain(mock, Retry(r))
+			start := time.Now()
+			resp, err := md.Do(ctx, types.NewRequest())
+			elapsed := time.Since(start)
+			require.Nil(t, resp)
+			require.Error(t, err)
+			require.EqualValues(t, tt.wantExecuted, mock.executed)
+			if tt.wantExecuted == 1 {
+				require.Equal(t, tt.err, err)
+			}
+			require.GreaterOrEqual(t, int64(elapsed), int64(tt.wantMinTime))
+			if tt.wantMaxTime > 0 {
+				require.Less(t, int64(elapsed), int64(tt.wantMaxTime))
+			}
+		})
+	}
+}
+
 func TestClient_Metric(t *testing.T) {
 	exporter, err := metrics.NewExporter()
 	require.NoError(t, err)
@@ -357,7 +730,7 @@ func TestClient_Metric(t *testing.T) {
 				Properties: nil,
 			},
 			wantReport: &metrics.Report{
-				Key:            "b-1-sn-sk-tn-tk",
+				Key:            "b-1-sk-tk",
 				Binding:        "b-1",
 				SourceKind:     "sk",
 				TargetKind:     "tk",
@@ -393,7 +766,7 @@ func TestClient_Metric(t *testing.T) {
 				Properties: nil,
 			},
 			wantReport: &metrics.Report{
-				Key:            "b-2-sn-sk-tn-tk",
+				Key:            "b-2-sk-tk",
 				Binding:        "b-2",
 				SourceKind:     "sk",
 				TargetKind:     "tk",
@@ -402,6 +775,7 @@ func TestClient_Metric(t *testing.T) {
 				ResponseCount:  0,
 				ResponseVolume: 0,
 				ErrorsCount:    1,
+				ErrorClasses:   map[string]float64{ErrorClassTarget: 1},
 			},
 			wantErr: false,
 		},
@@ -421,9 +795,55 @@ func TestClient_Metric(t *testing.T) {
 			md := Chain(tt.mock, Metric(m))
 			_, _ = md.Do(ctx, tt.mock.request)
 			storedReport := exporter.Store.Get(tt.wantReport.Key)
+			require.NotNil(t, storedReport)
+			require.Greater(t, storedReport.LatencySecondsSum, float64(0))
+			require.EqualValues(t, float64(0), storedReport.InFlight)
+			storedReport.LatencySecondsSum = 0
+			storedReport.LatencyAvgSeconds = 0
+			storedReport.LatencyMaxSeconds = 0
 			require.EqualValues(t, tt.wantReport, storedReport)
 		})
 	}
+	t.Run("retries and timeouts", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		mock := &mockTarget{
+			request: types.NewRequest(),
+			err:     fmt.Errorf("some-error"),
+			delay:   100 * time.Millisecond,
+		}
+		cfg := config.BindingConfig{
+			Name:   "b-3",
+			Source: config.Spec{Kind: "sk"},
+			Target: config.Spec{Kind: "tk"},
+			Properties: map[string]string{
+				"metrics_latency_buckets":  "0.01,0.1,1",
+				"retry_attempts":           "3",
+				"retry_delay_milliseconds": "10",
+				"retry_delay_type":         "fixed",
+				"timeout_milliseconds":     "10",
+			},
+		}
+		m, err := NewMetricsMiddleware(cfg, exporter)
+		require.NoError(t, err)
+		r, err := NewRetryMiddleware(cfg.Properties, nil)
+		require.NoError(t, err)
+		tm, err := NewTimeoutMiddleware(cfg.Properties)
+		require.NoError(t, err)
+		md := Chain(mock, Timeout(tm), Retry(r), Metric(m))
+		_, err = md.Do(ctx, mock.request)
+		require.Error(t, err)
+		storedReport := exporter.Store.Get("b-3-sk-tk")
+		require.NotNil(t, storedReport)
+		require.EqualValues(t, 2, storedReport.RetriesCount)
+		require.EqualValues(t, 1, storedReport.TimeoutsCount)
+		require.EqualValues(t, map[string]float64{ErrorClassTimeout: 1}, storedReport.ErrorClasses)
+	})
+	_, err = NewMetricsMiddleware(config.BindingConfig{
+		Name:       "b-4",
+		Properties: map[string]string{"metrics_latency_buckets": "1,0.5"},
+	}, exporter)
+	require.Error(t, err)
 }
 func TestClient_Log(t *testing.T) {
 
@@ -563,3 +983,1317 @@ func TestClient_Chain(t *testing.T) {
 	d := time.Since(start)
 	require.GreaterOrEqual(t, d.Milliseconds(), 2*time.Second.Milliseconds())
 }
+
+type mockDeadLetterSender struct {
+	channel string
+	body    []byte
+	err     error
+}
+
+func (m *mockDeadLetterSender) Send(ctx context.Context, channel string, body []byte) error {
+	m.channel = channel
+	m.body = body
+	return m.err
+}
+
+func (m *mockDeadLetterSender) Close() error {
+	return nil
+}
+
+func TestClient_DeadLetter(t *testing.T) {
+	tests := []struct {
+		name         string
+		sourceKind   string
+		mock         *mockTarget
+		sender       *mockDeadLetterSender
+		wantSent     bool
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name: "no error - not sent",
+			mock: &mockTarget{
+				request:  types.NewRequest().SetData([]byte("data")),
+				response: types.NewResponse(),
+				err:      nil,
+			},
+			sender:   &mockDeadLetterSender{},
+			wantSent: false,
+			wantErr:  false,
+		},
+		{
+			name: "error after retries - sent",
+			mock: &mockTarget{
+				request:  types.NewRequest().SetData([]byte("data")),
+				response: nil,
+				err:      fmt.Errorf("some-error"),
+			},
+			sender:       &mockDeadLetterSender{},
+			wantSent:     true,
+			wantAttempts: 3,
+			wantErr:      false,
+		},
+		{
+			name: "non retryable error after retries - sent",
+			mock: &mockTarget{
+				request:  types.NewRequest().SetData([]byte("data")),
+				response: nil,
+				errs:     []error{fmt.Errorf("some-error"), types.NewInvalidRequestError(fmt.Errorf("invalid-error"))},
+			},
+			sender:       &mockDeadLetterSender{},
+			wantSent:     true,
+			wantAttempts: 2,
+			wantErr:      false,
+		},
+		{
+			name: "error after retries - send failed",
+			mock: &mockTarget{
+				request:  types.NewRequest().SetData([]byte("data")),
+				response: nil,
+				err:      fmt.Errorf("some-error"),
+			},
+			sender: &mockDeadLetterSender{
+				err: fmt.Errorf("send-error"),
+			},
+			wantSent: true,
+			wantErr:  true,
+		},
+		{
+			name:       "circuit open on queue source - not sent",
+			sourceKind: "kubemq.queue",
+			mock: &mockTarget{
+				request:  types.NewRequest().SetData([]byte("data")),
+				response: nil,
+				err:      ErrCircuitOpen,
+			},
+			sender:   &mockDeadLetterSender{},
+			wantSent: false,
+			wantErr:  true,
+		},
+		{
+			name:       "circuit open on events source - sent",
+			sourceKind: "kubemq.events",
+			mock: &mockTarget{
+				request:  types.NewRequest().SetData([]byte("data")),
+				response: nil,
+				err:      ErrCircuitOpen,
+			},
+			sender:       &mockDeadLetterSender{},
+			wantSent:     true,
+			wantAttempts: 3,
+			wantErr:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			r, err := NewRetryMiddleware(map[string]string{
+				"retry_attempts":           "3",
+				"retry_delay_milliseconds": "10",
+				"retry_delay_type":         "fixed",
+			}, nil)
+			require.NoError(t, err)
+			dl := &DeadLetterMiddleware{
+				binding:    "b-1",
+				sourceKind: tt.sourceKind,
+				channel:    "dead-letter",
+				sender:     tt.sender,
+			}
+			md := Chain(tt.mock, Retry(r), DeadLetter(dl))
+			resp, err := md.Do(ctx, tt.mock.request)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			if !tt.wantSent {
+				require.Nil(t, tt.sender.body)
+				return
+			}
+			require.Equal(t, "dead-letter", tt.sender.channel)
+			require.NotNil(t, tt.sender.body)
+			if tt.wantErr {
+				return
+			}
+			require.True(t, resp.IsError)
+			msg := &DeadLetterMessage{}
+			require.NoError(t, json.Unmarshal(tt.sender.body, msg))
+			require.Equal(t, "b-1", msg.Binding)
+			require.Equal(t, tt.wantAttempts, msg.Attempts)
+			require.Equal(t, []byte("data"), msg.Request.Data)
+		})
+	}
+}
+func TestClient_CircuitBreaker(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	mock := &mockTarget{
+		request:  types.NewRequest(),
+		response: nil,
+		err:      fmt.Errorf("some-error"),
+		delay:    0,
+		executed: 0,
+	}
+	cfg := config.BindingConfig{
+		Name: "b-1",
+		Properties: map[string]string{
+			"circuit_breaker_failure_threshold":          "2",
+			"circuit_breaker_open_duration_milliseconds": "200",
+			"circuit_breaker_half_open_probes":           "1",
+		},
+	}
+	cb, err := NewCircuitBreakerMiddleware(cfg, nil)
+	require.NoError(t, err)
+	require.Equal(t, CircuitBreakerStateClosed, cb.State())
+	md := Chain(mock, CircuitBreaker(cb))
+	for i := 0; i < 2; i++ {
+		_, err = md.Do(ctx, mock.request)
+		require.EqualError(t, err, "some-error")
+	}
+	require.Equal(t, CircuitBreakerStateOpen, cb.State())
+	_, err = md.Do(ctx, mock.request)
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+	require.Equal(t, types.ErrorClassThrottled, types.ErrorClassOf(err))
+	require.True(t, types.RetryAfterOf(err) > 0 && types.RetryAfterOf(err) <= 200*time.Millisecond)
+	require.Equal(t, 2, mock.executed)
+
+	time.Sleep(250 * time.Millisecond)
+	_, err = md.Do(ctx, mock.request)
+	require.EqualError(t, err, "some-error")
+	require.Equal(t, CircuitBreakerStateOpen, cb.State())
+
+	time.Sleep(250 * time.Millisecond)
+	mock.err = nil
+	mock.response = types.NewResponse()
+	_, err = md.Do(ctx, mock.request)
+	require.NoError(t, err)
+	require.Equal(t, CircuitBreakerStateClosed, cb.State())
+
+	disabled, err := NewCircuitBreakerMiddleware(config.BindingConfig{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, CircuitBreakerStateDisabled, disabled.State())
+
+	_, err = NewCircuitBreakerMiddleware(config.BindingConfig{
+		Properties: map[string]string{"circuit_breaker_failure_threshold": "-1"},
+	}, nil)
+	require.Error(t, err)
+}
+func TestClient_CircuitBreakerStaleResults(t *testing.T) {
+	cb, err := NewCircuitBreakerMiddleware(config.BindingConfig{
+		Name: "b-1",
+		Properties: map[string]string{
+			"circuit_breaker_failure_threshold":          "1",
+			"circuit_breaker_open_duration_milliseconds": "100",
+			"circuit_breaker_half_open_probes":           "1",
+		},
+	}, nil)
+	require.NoError(t, err)
+	stale, _, ok := cb.allow()
+	require.True(t, ok)
+	failed, _, ok := cb.allow()
+	require.True(t, ok)
+	cb.onResult(failed, fmt.Errorf("some-error"))
+	require.Equal(t, CircuitBreakerStateOpen, cb.State())
+
+	time.Sleep(150 * time.Millisecond)
+	probe, _, ok := cb.allow()
+	require.True(t, ok)
+	require.Equal(t, CircuitBreakerStateHalfOpen, cb.State())
+	_, retryAfter, ok := cb.allow()
+	require.False(t, ok)
+	require.Equal(t, 100*time.Millisecond, retryAfter)
+
+	cb.onResult(stale, nil)
+	require.Equal(t, CircuitBreakerStateHalfOpen, cb.State())
+	cb.onResult(stale, fmt.Errorf("some-error"))
+	require.Equal(t, CircuitBreakerStateHalfOpen, cb.State())
+	cb.onResult(probe, nil)
+	require.Equal(t, CircuitBreakerStateClosed, cb.State())
+}
+func TestClient_CircuitBreakerRequestErrors(t *testing.T) {
+	cb, err := NewCircuitBreakerMiddleware(config.BindingConfig{
+		Name: "b-1",
+		Properties: map[string]string{
+			"circuit_breaker_failure_threshold":          "1",
+			"circuit_breaker_open_duration_milliseconds": "100",
+			"circuit_breaker_half_open_probes":           "1",
+		},
+	}, nil)
+	require.NoError(t, err)
+	requestErrors := []error{
+		types.NewInvalidRequestError(fmt.Errorf("invalid-error")),
+		types.NewNotFoundError(fmt.Errorf("not-found-error")),
+		types.NewConflictError(fmt.Errorf("conflict-error")),
+		&ValidationError{Violations: []Violation{{Field: "data", Description: "invalid"}}},
+		context.Canceled,
+		fmt.Errorf("request canceled, %w", context.Canceled),
+	}
+	for _, requestErr := range requestErrors {
+		generation, _, ok := cb.allow()
+		require.True(t, ok)
+		cb.onResult(generation, requestErr)
+		require.Equal(t, CircuitBreakerStateClosed, cb.State(), requestErr.Error())
+	}
+	generation, _, ok := cb.allow()
+	require.True(t, ok)
+	cb.onResult(generation, types.NewTransientError(fmt.Errorf("transient-error")))
+	require.Equal(t, CircuitBreakerStateOpen, cb.State())
+
+	time.Sleep(150 * time.Millisecond)
+	probe, _, ok := cb.allow()
+	require.True(t, ok)
+	cb.onResult(probe, context.Canceled)
+	require.Equal(t, CircuitBreakerStateHalfOpen, cb.State())
+	probe, _, ok = cb.allow()
+	require.True(t, ok)
+	cb.onResult(probe, nil)
+	require.Equal(t, CircuitBreakerStateClosed, cb.State())
+}
+func TestClient_Transform(t *testing.T) {
+	tests := []struct {
+		name         string
+		meta         types.Metadata
+		request      *types.Request
+		response     *types.Response
+		wantRequest  *types.Request
+		wantResponse *types.Response
+		wantErr      bool
+	}{
+		{
+			name:         "no transformation",
+			meta:         map[string]string{},
+			request:      types.NewRequest().SetMetadataKeyValue("method", "get").SetData([]byte(`{"id":1}`)),
+			response:     types.NewResponse().SetData([]byte("data")),
+			wantRequest:  types.NewRequest().SetMetadataKeyValue("method", "get").SetData([]byte(`{"id":1}`)),
+			wantResponse: types.NewResponse().SetData([]byte("data")),
+			wantErr:      false,
+		},
+		{
+			name: "request metadata from data, rename and drop",
+			meta: map[string]string{
+				"transform_request_metadata":      `{"key":"user-{{.Data.user.id}}","method":"{{.Metadata.action | lower}}"}`,
+				"transform_request_drop_metadata": "action",
+				"transform_request_data":          `{"name":{{toJson .Data.user.name}}}`,
+			},
+			request: types.NewRequest().SetMetadataKeyValue("action", "SET").SetData([]byte(`{"user":{"id":12,"name":"john"}}`)),
+			response: types.NewResponse().
+				SetData([]byte("data")),
+			wantRequest: types.NewRequest().
+				SetMetadataKeyValue("key", "user-12").
+				SetMetadataKeyValue("method", "set").
+				SetData([]byte(`{"name":"john"}`)),
+			wantResponse: types.NewResponse().SetData([]byte("data")),
+			wantErr:      false,
+		},
+		{
+			name: "response transformation",
+			meta: map[string]string{
+				"transform_response_metadata": `{"result":"{{default \"none\" .Data.result}}"}`,
+				"transform_response_data":     `{{.Data.rows}}`,
+			},
+			request:      types.NewRequest().SetData([]byte("data")),
+			response:     types.NewResponse().SetData([]byte(`{"rows":5}`)),
+			wantRequest:  types.NewRequest().SetData([]byte("data")),
+			wantResponse: types.NewResponse().SetMetadataKeyValue("result", "none").SetData([]byte("5")),
+			wantErr:      false,
+		},
+		{
+			name: "invalid template",
+			meta: map[string]string{
+				"transform_request_data": `{{.Data`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid metadata templates",
+			meta: map[string]string{
+				"transform_request_metadata": `not-json`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			tm, err := NewTransformMiddleware(tt.meta)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var gotRequest *types.Request
+			target := DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+				gotRequest = request
+				return tt.response, nil
+			})
+			md := Chain(target, Transform(tm))
+			gotResponse, err := md.Do(ctx, tt.request)
+			require.NoError(t, err)
+			require.EqualValues(t, tt.wantRequest, gotRequest)
+			require.EqualValues(t, tt.wantResponse, gotResponse)
+		})
+	}
+}
+func TestClient_Timeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		mock        *mockTarget
+		meta        types.Metadata
+		request     *types.Request
+		wantTimeout bool
+		wantErr     bool
+	}{
+		{
+			name: "no timeout",
+			mock: &mockTarget{
+				response: types.NewResponse(),
+				delay:    50 * time.Millisecond,
+			},
+			meta:        map[string]string{},
+			request:     types.NewRequest(),
+			wantTimeout: false,
+			wantErr:     false,
+		},
+		{
+			name: "binding timeout",
+			mock: &mockTarget{
+				response: types.NewResponse(),
+				delay:    500 * time.Millisecond,
+			},
+			meta: map[string]string{
+				"timeout_milliseconds": "50",
+			},
+			request:     types.NewRequest(),
+			wantTimeout: true,
+			wantErr:     true,
+		},
+		{
+			name: "request override timeout",
+			mock: &mockTarget{
+				response: types.NewResponse(),
+				delay:    100 * time.Millisecond,
+			},
+			meta: map[string]string{
+				"timeout_milliseconds": "50",
+			},
+			request:     types.NewRequest().SetMetadataKeyValue("request_timeout_milliseconds", "1000"),
+			wantTimeout: false,
+			wantErr:     false,
+		},
+		{
+			name: "invalid request timeout",
+			mock: &mockTarget{
+				response: types.NewResponse(),
+			},
+			meta:        map[string]string{},
+			request:     types.NewRequest().SetMetadataKeyValue("request_timeout_milliseconds", "bad"),
+			wantTimeout: false,
+			wantErr:     true,
+		},
+		{
+			name: "zero request timeout",
+			mock: &mockTarget{
+				response: types.NewResponse(),
+			},
+			meta:        map[string]string{},
+			request:     types.NewRequest().SetMetadataKeyValue("request_timeout_milliseconds", "0"),
+			wantTimeout: false,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			tm, err := NewTimeoutMiddleware(tt.meta)
+			require.NoError(t, err)
+			md := Chain(tt.mock, Timeout(tm))
+			_, err = md.Do(ctx, tt.request)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantTimeout, IsTimeoutError(err))
+		})
+	}
+	_, err := NewTimeoutMiddleware(map[string]string{"timeout_milliseconds": "-1"})
+	require.Error(t, err)
+	_, err = NewTimeoutMiddleware(map[string]string{"timeout_max_pending": "-1"})
+	require.Error(t, err)
+}
+
+func TestClient_TimeoutMaxPending(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	target := DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+		time.Sleep(200 * time.Millisecond)
+		return types.NewResponse(), nil
+	})
+	tm, err := NewTimeoutMiddleware(map[string]string{"timeout_milliseconds": "20", "timeout_max_pending": "1"})
+	require.NoError(t, err)
+	md := Chain(target, Timeout(tm))
+	_, err = md.Do(ctx, types.NewRequest())
+	require.True(t, IsTimeoutError(err))
+	_, err = md.Do(ctx, types.NewRequest())
+	require.Error(t, err)
+	require.False(t, IsTimeoutError(err))
+	require.Equal(t, types.ErrorClassTransient, types.ErrorClassOf(err))
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&tm.pending) == 0
+	}, time.Second, 10*time.Millisecond)
+	_, err = md.Do(ctx, types.NewRequest())
+	require.True(t, IsTimeoutError(err))
+}
+func TestClient_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	const (
+		traceId    = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpan = "00f067aa0ba902b7"
+	)
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+	}{
+		{
+			name:       "successful request",
+			err:        nil,
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "failed request",
+			err:        fmt.Errorf("some-error"),
+			wantStatus: codes.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			var headers map[string]string
+			target := DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+				headers = tracing.Headers(ctx)
+				return types.NewResponse(), tt.err
+			})
+			md := Chain(target, TraceTarget("target"), Trace("timeout", Timeout(&TimeoutMiddleware{})), TraceSource("b-1", "source"))
+			request := types.NewRequest().
+				SetMetadataKeyValue("traceparent", fmt.Sprintf("00-%s-%s-01", traceId, parentSpan))
+			_, err := md.Do(context.Background(), request)
+			require.Equal(t, tt.err, err)
+			require.Contains(t, headers["traceparent"], traceId)
+			require.NotContains(t, headers["traceparent"], parentSpan)
+
+			spans := exporter.GetSpans()
+			require.Equal(t, 3, len(spans))
+			names := map[string]*sdktrace.SpanSnapshot{}
+			for _, span := range spans {
+				require.Equal(t, traceId, span.SpanContext.TraceID().String())
+				require.Equal(t, tt.wantStatus, span.StatusCode)
+				names[span.Name] = span
+			}
+			require.Equal(t, parentSpan, names["source source"].Parent.SpanID().String())
+			require.Equal(t, names["source source"].SpanContext.SpanID(), names["middleware timeout"].Parent.SpanID())
+			require.Equal(t, names["middleware timeout"].SpanContext.SpanID(), names["target target"].Parent.SpanID())
+		})
+	}
+}
+
+type mockCacheTarget struct {
+	sync.Mutex
+	items map[string][]byte
+	ttls  map[string]string
+}
+
+func (m *mockCacheTarget) Do(ctx context.Context, request *types.Request) (*types.Response, error) {
+	m.Lock()
+	defer m.Unlock()
+	key := request.Metadata.Get("key")
+	switch request.Metadata.Get("method") {
+	case "set":
+		m.items[key] = request.Data
+		if m.ttls != nil {
+			m.ttls[key] = request.Metadata.Get("ttl_seconds")
+		}
+		return types.NewResponse(), nil
+	default:
+		data, ok := m.items[key]
+		if !ok {
+			return nil, fmt.Errorf("key not found")
+		}
+		return types.NewResponse().SetData(data), nil
+	}
+}
+
+func TestClient_Dedup(t *testing.T) {
+	tests := []struct {
+		name         string
+		meta         types.Metadata
+		store        DedupStore
+		requests     []*types.Request
+		wait         time.Duration
+		err          error
+		wantExecuted int
+	}{
+		{
+			name:         "disabled",
+			meta:         types.Metadata{},
+			requests:     []*types.Request{types.NewRequest().SetData([]byte("data")), types.NewRequest().SetData([]byte("data"))},
+			wantExecuted: 2,
+		},
+		{
+			name: "dedup by request hash",
+			meta: types.Metadata{"dedup_ttl_seconds": "10"},
+			requests: []*types.Request{
+				types.NewRequest().SetMetadataKeyValue("id", "1").SetData([]byte("data")),
+				types.NewRequest().SetMetadataKeyValue("id", "1").SetData([]byte("data")),
+				types.NewRequest().SetMetadataKeyValue("id", "1").SetData([]byte("other-data")),
+			},
+			wantExecuted: 2,
+		},
+		{
+			name: "dedup redelivery by request hash",
+			meta: types.Metadata{"dedup_ttl_seconds": "10"},
+			requests: []*types.Request{
+				types.NewRequest().
+					SetMetadataKeyValue("id", "1").
+					SetMetadataKeyValue("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01").
+					SetMetadataKeyValue("kubemq_message_id", "message-1").
+					SetMetadataKeyValue("kubemq_receive_count", "1").
+					SetData([]byte("data")),
+				types.NewRequest().
+					SetMetadataKeyValue("id", "1").
+					SetMetadataKeyValue("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01").
+					SetMetadataKeyValue("kubemq_message_id", "message-1").
+					SetMetadataKeyValue("kubemq_receive_count", "2").
+					SetData([]byte("data")),
+			},
+			wantExecuted: 1,
+		},
+		{
+			name: "dedup by metadata key",
+			meta: types.Metadata{"dedup_ttl_seconds": "10", "dedup_key": "id"},
+			requests: []*types.Request{
+				types.NewRequest().SetMetadataKeyValue("id", "1").SetData([]byte("data")),
+				types.NewRequest().SetMetadataKeyValue("id", "1").SetData([]byte("other-data")),
+				types.NewRequest().SetMetadataKeyValue("id", "2").SetData([]byte("data")),
+				types.NewRequest().SetData([]byte("data")),
+				types.NewRequest().SetData([]byte("data")),
+			},
+			wantExecuted: 4,
+		},
+		{
+			name: "dedup entry expired",
+			meta: types.Metadata{"dedup_ttl_seconds": "1", "dedup_key": "id"},
+			requests: []*types.Request{
+				types.NewRequest().SetMetadataKeyValue("id", "1"),
+				types.NewRequest().SetMetadataKeyValue("id", "1"),
+			},
+			wait:         1100 * time.Millisecond,
+			wantExecuted: 2,
+		},
+		{
+			name: "failed requests are not deduped",
+			meta: types.Metadata{"dedup_ttl_seconds": "10", "dedup_key": "id"},
+			requests: []*types.Request{
+				types.NewRequest().SetMetadataKeyValue("id", "1"),
+				types.NewRequest().SetMetadataKeyValue("id", "1"),
+			},
+			err:          fmt.Errorf("some-error"),
+			wantExecuted: 2,
+		},
+		{
+			name: "lru eviction",
+			meta: types.Metadata{"dedup_ttl_seconds": "10", "dedup_key": "id", "dedup_max_entries": "1"},
+			requests: []*types.Request{
+				types.NewRequest().SetMetadataKeyValue("id", "1"),
+				types.NewRequest().SetMetadataKeyValue("id", "2"),
+				types.NewRequest().SetMetadataKeyValue("id", "1"),
+			},
+			wantExecuted: 3,
+		},
+		{
+			name:  "target store",
+			meta:  types.Metadata{"dedup_ttl_seconds": "10", "dedup_key": "id"},
+			store: NewTargetDedupStore("dedup/b-1/", &mockCacheTarget{items: map[string][]byte{}}),
+			requests: []*types.Request{
+				types.NewRequest().SetMetadataKeyValue("id", "1"),
+				types.NewRequest().SetMetadataKeyValue("id", "1"),
+				types.NewRequest().SetMetadataKeyValue("id", "2"),
+			},
+			wantExecuted: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			mock := &mockTarget{
+				response: types.NewResponse().SetMetadataKeyValue("result", "ok"),
+				err:      tt.err,
+			}
+			d, err := NewDedupMiddleware(tt.meta, tt.store)
+			require.NoError(t, err)
+			md := Chain(mock, Dedup(d))
+			for i, request := range tt.requests {
+				if i > 0 {
+					time.Sleep(tt.wait)
+				}
+				resp, err := md.Do(ctx, request)
+				if tt.err != nil {
+					require.EqualError(t, err, tt.err.Error())
+					continue
+				}
+				require.NoError(t, err)
+				require.Equal(t, "ok", resp.Metadata.Get("result"))
+			}
+			require.Equal(t, tt.wantExecuted, mock.executed)
+		})
+	}
+}
+
+func TestTargetDedupStore(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	target := &mockCacheTarget{items: map[string][]byte{}, ttls: map[string]string{}}
+	store := NewTargetDedupStore("dedup/b-1/", target)
+	require.NoError(t, store.Set(ctx, "key-1", types.NewResponse().SetMetadataKeyValue("result", "ok"), 10*time.Second))
+	require.Equal(t, "10", target.ttls["dedup/b-1/key-1"])
+	resp, ok, err := store.Get(ctx, "key-1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "ok", resp.Metadata.Get("result"))
+	_, ok, err = store.Get(ctx, "key-2")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestClient_DedupConcurrent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	executed := int32(0)
+	target := DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+		atomic.AddInt32(&executed, 1)
+		time.Sleep(100 * time.Millisecond)
+		return types.NewResponse(), nil
+	})
+	d, err := NewDedupMiddleware(types.Metadata{"dedup_ttl_seconds": "10", "dedup_key": "id"}, nil)
+	require.NoError(t, err)
+	md := Chain(target, Dedup(d))
+	wg := sync.WaitGroup{}
+	responses := make([]*types.Response, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := md.Do(ctx, types.NewRequest().SetMetadataKeyValue("id", "1"))
+			require.NoError(t, err)
+			resp.SetMetadataKeyValue("caller", fmt.Sprintf("%d", i))
+			responses[i] = resp
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&executed))
+	for i, resp := range responses {
+		require.Equal(t, fmt.Sprintf("%d", i), resp.Metadata.Get("caller"))
+	}
+	resp, err := md.Do(ctx, types.NewRequest().SetMetadataKeyValue("id", "1"))
+	require.NoError(t, err)
+	require.Equal(t, "", resp.Metadata.Get("caller"))
+
+	_, err = NewDedupMiddleware(types.Metadata{"dedup_store": "bad-store"}, nil)
+	require.Error(t, err)
+}
+
+type mockBatchTarget struct {
+	mu        sync.Mutex
+	batches   []int
+	deadlines []bool
+	single    int32
+	err       error
+}
+
+func (m *mockBatchTarget) Do(ctx context.Context, request *types.Request) (*types.Response, error) {
+	atomic.AddInt32(&m.single, 1)
+	return types.NewResponse().SetMetadataKeyValue("result", "ok"), nil
+}
+
+func (m *mockBatchTarget) DoBatch(ctx context.Context, requests []*types.Request) ([]*types.Response, []error, error) {
+	m.mu.Lock()
+	m.batches = append(m.batches, len(requests))
+	_, hasDeadline := ctx.Deadline()
+	m.deadlines = append(m.deadlines, hasDeadline)
+	m.mu.Unlock()
+	if m.err != nil {
+		return nil, nil, m.err
+	}
+	responses := make([]*types.Response, len(requests))
+	errs := make([]error, len(requests))
+	for i, request := range requests {
+		switch {
+		case request.Metadata.Get("fail") != "":
+			errs[i] = fmt.Errorf("item-error")
+		case request.Metadata.Get("error_response") != "":
+			responses[i] = types.NewResponse().SetMetadataKeyValue("result", "ok").SetError(fmt.Errorf("response-error"))
+		default:
+			responses[i] = types.NewResponse().SetMetadataKeyValue("result", "ok")
+		}
+	}
+	return responses, errs, nil
+}
+
+func TestClient_Batch(t *testing.T) {
+	tests := []struct {
+		name        string
+		meta        types.Metadata
+		noBatch     bool
+		err         error
+		requests    []*types.Request
+		wantBatches []int
+		wantSingle  int32
+		wantErrors  int
+	}{
+		{
+			name:       "disabled",
+			meta:       types.Metadata{},
+			requests:   []*types.Request{types.NewRequest(), types.NewRequest()},
+			wantSingle: 2,
+		},
+		{
+			name:       "target without batch support",
+			meta:       types.Metadata{"batch_max_requests": "2"},
+			noBatch:    true,
+			requests:   []*types.Request{types.NewRequest(), types.NewRequest()},
+			wantSingle: 2,
+		},
+		{
+			name:        "flush on max requests",
+			meta:        types.Metadata{"batch_max_requests": "2", "batch_max_wait_milliseconds": "10000"},
+			requests:    []*types.Request{types.NewRequest(), types.NewRequest(), types.NewRequest(), types.NewRequest()},
+			wantBatches: []int{2, 2},
+		},
+		{
+			name:        "flush on max wait",
+			meta:        types.Metadata{"batch_max_requests": "10", "batch_max_wait_milliseconds": "50"},
+			requests:    []*types.Request{types.NewRequest(), types.NewRequest(), types.NewRequest()},
+			wantBatches: []int{3},
+		},
+		{
+			name: "per request errors",
+			meta: types.Metadata{"batch_max_requests": "3"},
+			requests: []*types.Request{
+				types.NewRequest(),
+				types.NewRequest().SetMetadataKeyValue("fail", "true"),
+				types.NewRequest(),
+			},
+			wantBatches: []int{3},
+			wantErrors:  1,
+		},
+		{
+			name: "error responses are not errors",
+			meta: types.Metadata{"batch_max_requests": "2"},
+			requests: []*types.Request{
+				types.NewRequest(),
+				types.NewRequest().SetMetadataKeyValue("error_response", "true"),
+			},
+			wantBatches: []int{2},
+		},
+		{
+			name:        "batch error",
+			meta:        types.Metadata{"batch_max_requests": "2"},
+			err:         fmt.Errorf("batch-error"),
+			requests:    []*types.Request{types.NewRequest(), types.NewRequest()},
+			wantBatches: []int{2},
+			wantErrors:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			mock := &mockBatchTarget{err: tt.err}
+			var target Middleware = mock
+			if tt.noBatch {
+				target = DoFunc(mock.Do)
+			}
+			b, err := NewBatchMiddleware(ctx, tt.meta, target)
+			require.NoError(t, err)
+			md := Chain(target, Batch(b))
+			wg := sync.WaitGroup{}
+			errCount := int32(0)
+			for _, request := range tt.requests {
+				wg.Add(1)
+				go func(request *types.Request) {
+					defer wg.Done()
+					resp, err := md.Do(ctx, request)
+					if err != nil {
+						atomic.AddInt32(&errCount, 1)
+						return
+					}
+					require.Equal(t, "ok", resp.Metadata.Get("result"))
+				}(request)
+			}
+			wg.Wait()
+			require.Equal(t, int32(tt.wantErrors), errCount)
+			require.Equal(t, tt.wantSingle, atomic.LoadInt32(&mock.single))
+			require.Equal(t, tt.wantBatches, mock.batches)
+			for _, hasDeadline := range mock.deadlines {
+				require.True(t, hasDeadline)
+			}
+		})
+	}
+	_, err := NewBatchMiddleware(context.Background(), types.Metadata{"batch_max_requests": "-1"}, nil)
+	require.Error(t, err)
+}
+
+func TestClient_BatchCanceledRequests(t *testing.T) {
+	mock := &mockBatchTarget{}
+	b, err := NewBatchMiddleware(context.Background(), types.Metadata{"batch_max_requests": "10", "batch_max_wait_milliseconds": "100"}, mock)
+	require.NoError(t, err)
+	md := Chain(mock, Batch(b))
+	canceledCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := md.Do(canceledCtx, types.NewRequest())
+		require.Error(t, err)
+	}()
+	resp, err := md.Do(context.Background(), types.NewRequest())
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp.Metadata.Get("result"))
+	wg.Wait()
+	require.Equal(t, []int{1}, mock.batches)
+	require.Equal(t, []bool{false}, mock.deadlines)
+}
+
+func TestClient_Validation(t *testing.T) {
+	connector := common.NewConnector().
+		SetKind("cache.redis").
+		AddMetadata(
+			common.NewMetadata().
+				SetName("method").
+				SetKind("string").
+				SetOptions([]string{"get", "set", "delete"}).
+				SetMust(true)).
+		AddMetadata(
+			common.NewMetadata().
+				SetName("etag").
+				SetKind("int").
+				SetMust(false)).
+		AddMetadata(
+			common.NewMetadata().
+				SetName("consistency").
+				SetKind("string").
+				SetOptions([]string{"strong", "eventual"}).
+				SetMust(false))
+	schemaFile, err := ioutil.TempFile("", "schema-*.json")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Remove(schemaFile.Name())
+	}()
+	_, err = schemaFile.WriteString(`{"type":"object","required":["id"],"properties":{"id":{"type":"integer"}}}`)
+	require.NoError(t, err)
+	require.NoError(t, schemaFile.Close())
+	tests := []struct {
+		name           string
+		meta           types.Metadata
+		request        *types.Request
+		wantViolations []string
+		wantErr        bool
+	}{
+		{
+			name:    "disabled",
+			meta:    types.Metadata{},
+			request: types.NewRequest().SetData([]byte("not-json")),
+		},
+		{
+			name:    "valid metadata",
+			meta:    types.Metadata{"validation_metadata_schema": `{"type":"object","required":["key"]}`},
+			request: types.NewRequest().SetMetadataKeyValue("key", "some-key"),
+		},
+		{
+			name:           "invalid metadata",
+			meta:           types.Metadata{"validation_metadata_schema": `{"type":"object","required":["key"]}`},
+			request:        types.NewRequest(),
+			wantViolations: []string{"metadata"},
+		},
+		{
+			name:    "valid data from file",
+			meta:    types.Metadata{"validation_data_schema_file": schemaFile.Name()},
+			request: types.NewRequest().SetData([]byte(`{"id":1}`)),
+		},
+		{
+			name:           "invalid data",
+			meta:           types.Metadata{"validation_data_schema_file": schemaFile.Name()},
+			request:        types.NewRequest().SetData([]byte(`{"id":"1"}`)),
+			wantViolations: []string{"data.id"},
+		},
+		{
+			name:           "data is not json",
+			meta:           types.Metadata{"validation_data_schema_file": schemaFile.Name()},
+			request:        types.NewRequest().SetData([]byte(`not-json`)),
+			wantViolations: []string{"data"},
+		},
+		{
+			name:    "valid connector metadata",
+			meta:    types.Metadata{"validation_connector_schema": "true"},
+			request: types.NewRequest().SetMetadataKeyValue("method", "get").SetMetadataKeyValue("etag", "10").SetMetadataKeyValue("other", "value"),
+		},
+		{
+			name: "all violations are listed",
+			meta: types.Metadata{
+				"validation_connector_schema": "true",
+				"validation_data_schema_file": schemaFile.Name(),
+			},
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "bad-method").
+				SetMetadataKeyValue("etag", "bad-etag").
+				SetMetadataKeyValue("consistency", "bad-consistency").
+				SetData([]byte(`{}`)),
+			wantViolations: []string{"metadata.method", "metadata.etag", "metadata.consistency", "data"},
+		},
+		{
+			name:           "connector method is required",
+			meta:           types.Metadata{"validation_connector_schema": "true"},
+			request:        types.NewRequest(),
+			wantViolations: []string{"metadata"},
+		},
+		{
+			name:    "invalid schema",
+			meta:    types.Metadata{"validation_metadata_schema": `{"type":"bad-type"}`},
+			wantErr: true,
+		},
+		{
+			name:    "inline and file schema",
+			meta:    types.Metadata{"validation_data_schema": `{}`, "validation_data_schema_file": schemaFile.Name()},
+			wantErr: true,
+		},
+		{
+			name:    "schema file not found",
+			meta:    types.Metadata{"validation_data_schema_file": "not-found.json"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			v, err := NewValidationMiddleware(tt.meta, connector)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			mock := &mockTarget{
+				response: types.NewResponse().SetMetadataKeyValue("result", "ok"),
+			}
+			md := Chain(mock, Validation(v))
+			resp, err := md.Do(ctx, tt.request)
+			if len(tt.wantViolations) == 0 {
+				require.NoError(t, err)
+				require.Equal(t, "ok", resp.Metadata.Get("result"))
+				require.Equal(t, 1, mock.executed)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, 0, mock.executed)
+			validationErr, ok := err.(*ValidationError)
+			require.True(t, ok)
+			var fields []string
+			for _, violation := range validationErr.Violations {
+				fields = append(fields, violation.Field)
+				require.NotEmpty(t, violation.Description)
+			}
+			require.ElementsMatch(t, tt.wantViolations, fields)
+		})
+	}
+}
+
+func TestClient_TransformValidation(t *testing.T) {
+	connector := common.NewConnector().
+		SetKind("cache.redis").
+		AddMetadata(
+			common.NewMetadata().
+				SetName("method").
+				SetKind("string").
+				SetOptions([]string{"get", "set", "delete"}).
+				SetMust(true))
+	meta := types.Metadata{
+		"validation_connector_schema": "true",
+		"transform_request_metadata":  `{"method":"{{.Metadata.action | lower}}"}`,
+	}
+	tests := []struct {
+		name    string
+		request *types.Request
+		wantErr bool
+	}{
+		{
+			name:    "method set by transform",
+			request: types.NewRequest().SetMetadataKeyValue("action", "GET"),
+		},
+		{
+			name:    "invalid method set by transform",
+			request: types.NewRequest().SetMetadataKeyValue("action", "BAD"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			tm, err := NewTransformMiddleware(meta)
+			require.NoError(t, err)
+			v, err := NewValidationMiddleware(meta, connector)
+			require.NoError(t, err)
+			mock := &mockTarget{
+				response: types.NewResponse().SetMetadataKeyValue("result", "ok"),
+			}
+			md := Chain(mock, Validation(v), Transform(tm))
+			resp, err := md.Do(ctx, tt.request)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, 0, mock.executed)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "ok", resp.Metadata.Get("result"))
+			require.Equal(t, 1, mock.executed)
+		})
+	}
+}
+
+func TestClient_Cache(t *testing.T) {
+	get := func(key string) *types.Request {
+		return types.NewRequest().SetMetadataKeyValue("method", "get").SetMetadataKeyValue("key", key)
+	}
+	set := func(key string) *types.Request {
+		return types.NewRequest().SetMetadataKeyValue("method", "set").SetMetadataKeyValue("key", key).SetData([]byte("data"))
+	}
+	tests := []struct {
+		name         string
+		meta         types.Metadata
+		requests     []*types.Request
+		wait         time.Duration
+		err          error
+		wantExecuted int
+		wantHits     int64
+		wantMisses   int64
+		wantErr      bool
+	}{
+		{
+			name:         "disabled",
+			meta:         types.Metadata{},
+			requests:     []*types.Request{get("1"), get("1")},
+			wantExecuted: 2,
+		},
+		{
+			name:         "cache allowed methods",
+			meta:         types.Metadata{"cache_methods": "get"},
+			requests:     []*types.Request{get("1"), get("1"), get("2"), set("1"), set("1")},
+			wantExecuted: 4,
+			wantHits:     1,
+			wantMisses:   2,
+		},
+		{
+			name:         "cache entry expired",
+			meta:         types.Metadata{"cache_methods": "get", "cache_ttl_seconds": "1"},
+			requests:     []*types.Request{get("1"), get("1")},
+			wait:         1100 * time.Millisecond,
+			wantExecuted: 2,
+			wantMisses:   2,
+		},
+		{
+			name:         "failed requests are not cached",
+			meta:         types.Metadata{"cache_methods": "get"},
+			requests:     []*types.Request{get("1"), get("1")},
+			err:          fmt.Errorf("some-error"),
+			wantExecuted: 2,
+			wantMisses:   2,
+		},
+		{
+			name:         "lru eviction",
+			meta:         types.Metadata{"cache_methods": "get", "cache_max_entries": "1"},
+			requests:     []*types.Request{get("1"), get("2"), get("1")},
+			wantExecuted: 3,
+			wantMisses:   3,
+		},
+		{
+			name:         "max size",
+			meta:         types.Metadata{"cache_methods": "get", "cache_max_size_bytes": "1"},
+			requests:     []*types.Request{get("1"), get("1")},
+			wantExecuted: 2,
+			wantMisses:   2,
+		},
+		{
+			name: "cache traced requests",
+			meta: types.Metadata{"cache_methods": "get"},
+			requests: []*types.Request{
+				get("1").SetMetadataKeyValue("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"),
+				get("1").SetMetadataKeyValue("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01").
+					SetMetadataKeyValue("kubemq_timestamp", "1600000000"),
+			},
+			wantExecuted: 1,
+			wantHits:     1,
+			wantMisses:   1,
+		},
+		{
+			name:         "invalidate on write method",
+			meta:         types.Metadata{"cache_methods": "get", "cache_invalidate_methods": "set"},
+			requests:     []*types.Request{get("1"), get("1"), set("1"), get("1")},
+			wantExecuted: 3,
+			wantHits:     1,
+			wantMisses:   2,
+		},
+		{
+			name:         "invalidate on any not cached method",
+			meta:         types.Metadata{"cache_methods": "get", "cache_invalidate_methods": "*"},
+			requests:     []*types.Request{get("1"), set("1"), get("1")},
+			wantExecuted: 3,
+			wantMisses:   2,
+		},
+		{
+			name:    "bad invalidate methods",
+			meta:    types.Metadata{"cache_methods": "get", "cache_invalidate_methods": "get"},
+			wantErr: true,
+		},
+		{
+			name:    "bad ttl",
+			meta:    types.Metadata{"cache_methods": "get", "cache_ttl_seconds": "0"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCacheMiddleware(tt.meta)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			mock := &mockTarget{
+				response: types.NewResponse().SetData([]byte("response")),
+				err:      tt.err,
+			}
+			md := Chain(mock, Cache(c))
+			ctx, stats := withRequestStats(context.Background())
+			for i, request := range tt.requests {
+				if i == len(tt.requests)-1 && tt.wait > 0 {
+					time.Sleep(tt.wait)
+				}
+				resp, err := md.Do(ctx, request)
+				if tt.err != nil {
+					require.Error(t, err)
+					continue
+				}
+				require.NoError(t, err)
+				require.Equal(t, []byte("response"), resp.Data)
+			}
+			require.Equal(t, tt.wantExecuted, mock.executed)
+			require.Equal(t, tt.wantHits, stats.getCacheHits())
+			require.Equal(t, tt.wantMisses, stats.getCacheMisses())
+		})
+	}
+}
+
+func TestClient_Recorder(t *testing.T) {
+	tests := []struct {
+		name        string
+		meta        types.Metadata
+		requests    int
+		err         error
+		wantRecords int
+		wantErr     bool
+	}{
+		{
+			name:        "disabled",
+			meta:        types.Metadata{},
+			requests:    2,
+			wantRecords: 0,
+		},
+		{
+			name:        "record all requests",
+			meta:        types.Metadata{"record_redact_keys": "token"},
+			requests:    3,
+			wantRecords: 3,
+		},
+		{
+			name:        "record failed requests",
+			meta:        types.Metadata{"record_redact_keys": "token"},
+			requests:    2,
+			err:         fmt.Errorf("some-error"),
+			wantRecords: 2,
+		},
+		{
+			name:    "bad sample percent",
+			meta:    types.Metadata{"record_sample_percent": "0"},
+			wantErr: true,
+		},
+		{
+			name:    "bad max size",
+			meta:    types.Metadata{"record_max_size_mb": "0"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "recorder")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			filename := dir + "/records.jsonl"
+			if tt.wantRecords > 0 {
+				tt.meta["record_file"] = filename
+			}
+			r, err := NewRecorderMiddleware("binding", tt.meta, logger.NewLogger("TestClient_Recorder"))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			mock := &mockTarget{
+				response: types.NewResponse().SetMetadataKeyValue("token", "response-secret").SetData([]byte(`{"result":"ok"}`)),
+				err:      tt.err,
+			}
+			md := Chain(mock, Recorder(r))
+			for i := 0; i < tt.requests; i++ {
+				request := types.NewRequest().
+					SetMetadataKeyValue("method", "get").
+					SetMetadataKeyValue("token", "secret").
+					SetData([]byte(fmt.Sprintf("data-%d", i)))
+				_, _ = md.Do(context.Background(), request)
+				require.Equal(t, "secret", request.Metadata.Get("token"))
+			}
+			require.NoError(t, r.Close())
+			if tt.wantRecords == 0 {
+				_, err := os.Stat(filename)
+				require.True(t, os.IsNotExist(err))
+				return
+			}
+			file, err := os.Open(filename)
+			require.NoError(t, err)
+			defer file.Close()
+			records, err := ReadRecords(file)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRecords, len(records))
+			for i, record := range records {
+				require.Equal(t, "binding", record.Binding)
+				require.Equal(t, "get", record.Request.Metadata.Get("method"))
+				require.Equal(t, recordRedactedValue, record.Request.Metadata.Get("token"))
+				require.Equal(t, []byte(fmt.Sprintf("data-%d", i)), record.Request.Data)
+				if tt.err != nil {
+					require.Equal(t, tt.err.Error(), record.Error)
+					continue
+				}
+				require.Empty(t, record.Error)
+				require.Equal(t, recordRedactedValue, record.Response.Metadata.Get("token"))
+				require.Equal(t, []byte(`{"result":"ok"}`), record.Response.Data)
+			}
+		})
+	}
+}
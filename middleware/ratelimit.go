@@ -1,14 +1,52 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/kubemq-hub/kubemq-targets/pkg/ratelimit"
 	"github.com/kubemq-hub/kubemq-targets/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
+const (
+	defaultRateBurst                   = 1
+	defaultRateAdaptiveMinPerSeconds   = 1
+	defaultRateAdaptiveRecoverySeconds = 30
+	rateAdaptiveBackoffFactor          = 0.5
+	rateAdaptiveBackoffInterval        = time.Second
+)
+
+// throttlingCodes are the error codes of provider throttling errors, lower cased, such as aws ThrottlingException
+var throttlingCodes = map[string]bool{
+	"throttling":                             true,
+	"throttlingexception":                    true,
+	"throttledexception":                     true,
+	"requestthrottled":                       true,
+	"requestthrottledexception":              true,
+	"toomanyrequests":                        true,
+	"toomanyrequestsexception":               true,
+	"requestlimitexceeded":                   true,
+	"provisionedthroughputexceededexception": true,
+	"slowdown":                               true,
+}
+
 type RateLimitMiddleware struct {
-	rateLimiter ratelimit.Limiter
+	bucket       *ratelimit.TokenBucket
+	inFlight     chan struct{}
+	adaptive     bool
+	maxRate      float64
+	minRate      float64
+	recoveryRate float64
+	mu           sync.Mutex
+	lastAdjust   time.Time
+	lastBackoff  time.Time
 }
 
 func NewRateLimitMiddleware(meta types.Metadata) (*RateLimitMiddleware, error) {
@@ -16,16 +54,121 @@ func NewRateLimitMiddleware(meta types.Metadata) (*RateLimitMiddleware, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid rate limiter rate per second value, %w", err)
 	}
-	rl := &RateLimitMiddleware{}
+	burst, err := meta.ParseIntWithRange("rate_burst", defaultRateBurst, 1, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limiter burst value, %w", err)
+	}
+	maxInFlight, err := meta.ParseIntWithRange("rate_max_in_flight", 0, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limiter max in flight value, %w", err)
+	}
+	rl := &RateLimitMiddleware{
+		adaptive: meta.ParseBool("rate_adaptive", false),
+	}
+	if maxInFlight > 0 {
+		rl.inFlight = make(chan struct{}, maxInFlight)
+	}
 	if rpc > 0 {
-		rl.rateLimiter = ratelimit.New(rpc, ratelimit.WithoutSlack)
-	} else {
-		rl.rateLimiter = ratelimit.NewUnlimited()
+		rl.bucket = ratelimit.NewTokenBucket(float64(rpc), burst)
+	}
+	if !rl.adaptive {
+		return rl, nil
 	}
+	if rpc == 0 {
+		return nil, fmt.Errorf("invalid rate limiter adaptive mode, rate_per_seconds must be set")
+	}
+	minRate, err := meta.ParseIntWithRange("rate_adaptive_min_per_seconds", defaultRateAdaptiveMinPerSeconds, 1, rpc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limiter adaptive min rate per second value, %w", err)
+	}
+	recovery, err := meta.ParseIntWithRange("rate_adaptive_recovery_seconds", defaultRateAdaptiveRecoverySeconds, 1, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limiter adaptive recovery seconds value, %w", err)
+	}
+	rl.maxRate = float64(rpc)
+	rl.minRate = float64(minRate)
+	rl.recoveryRate = (rl.maxRate - rl.minRate) / float64(recovery)
+	rl.lastAdjust = time.Now()
 	return rl, nil
 }
 
-func (rl *RateLimitMiddleware) Take() {
-	_ = rl.rateLimiter.Take()
+// wait blocks until a concurrency slot and a rate token are available or the context is done, and returns the time waited
+func (rl *RateLimitMiddleware) wait(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	if rl.inFlight != nil {
+		select {
+		case rl.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return time.Since(start), ctx.Err()
+		}
+	}
+	if rl.bucket != nil {
+		if err := rl.bucket.Wait(ctx); err != nil {
+			rl.release()
+			return time.Since(start), err
+		}
+	}
+	return time.Since(start), nil
+}
+
+func (rl *RateLimitMiddleware) release() {
+	if rl.inFlight != nil {
+		<-rl.inFlight
+	}
+}
+
+// onResult adapts the rate to the target result, the rate is cut on throttling, at most once per backoff interval
+// so concurrent throttled requests count once, and recovers linearly over the recovery period
+func (rl *RateLimitMiddleware) onResult(resp *types.Response, err error) {
+	if !rl.adaptive {
+		return
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := time.Now()
+	rate := rl.bucket.Rate()
+	if IsThrottlingError(resp, err) {
+		if now.Sub(rl.lastBackoff) < rateAdaptiveBackoffInterval {
+			return
+		}
+		rl.lastBackoff = now
+		rate = math.Max(rl.minRate, rate*rateAdaptiveBackoffFactor)
+	} else {
+		rate = math.Min(rl.maxRate, rate+now.Sub(rl.lastAdjust).Seconds()*rl.recoveryRate)
+	}
+	rl.lastAdjust = now
+	rl.bucket.SetRate(rate)
+}
+
+// Rate returns the current rate per second, 0 when unlimited
+func (rl *RateLimitMiddleware) Rate() float64 {
+	if rl.bucket == nil {
+		return 0
+	}
+	return rl.bucket.Rate()
+}
 
+// IsThrottlingError reports whether the target throttled the request: a throttled class error, a provider error with a
+// throttling code, such as aws ThrottlingException, or a 429 http status code, a grpc resource exhausted error, or a
+// response with http status code 429. Error messages are not matched, as they may contain any text
+func IsThrottlingError(resp *types.Response, err error) bool {
+	if resp != nil && resp.Metadata.Get("code") == "429" {
+		return true
+	}
+	if err == nil {
+		return false
+	}
+	if types.ErrorClassOf(err) == types.ErrorClassThrottled {
+		return true
+	}
+	var coder interface{ Code() string }
+	if errors.As(err, &coder) && throttlingCodes[strings.ToLower(coder.Code())] {
+		return true
+	}
+	var statusCoder interface{ StatusCode() int }
+	if errors.As(err, &statusCoder) && statusCoder.StatusCode() == http.StatusTooManyRequests {
+		return true
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	return errors.As(err, &grpcErr) && grpcErr.GRPCStatus().Code() == codes.ResourceExhausted
 }
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const (
+	defaultRecordSamplePercent = 100
+	defaultRecordMaxSizeMB     = 100
+	defaultRecordMaxBackups    = 5
+	recordRedactedValue        = "[redacted]"
+	maxRecordLineBytes         = 64 * 1024 * 1024
+)
+
+// Record is a recorded target request with its response or error, one json line per record in a recording file
+type Record struct {
+	Binding             string          `json:"binding"`
+	Time                time.Time       `json:"time"`
+	LatencyMilliseconds int64           `json:"latency_milliseconds"`
+	Request             *types.Request  `json:"request"`
+	Response            *types.Response `json:"response,omitempty"`
+	Error               string          `json:"error,omitempty"`
+}
+
+// RedactedKeys returns the sorted request metadata keys of which values were redacted by the recorder
+func (r *Record) RedactedKeys() []string {
+	if r.Request == nil {
+		return nil
+	}
+	var keys []string
+	for key, value := range r.Request.Metadata {
+		if value == recordRedactedValue {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type RecorderMiddleware struct {
+	binding       string
+	samplePercent int
+	redactKeys    map[string]bool
+	writer        io.WriteCloser
+	log           *logger.Logger
+}
+
+func NewRecorderMiddleware(binding string, meta types.Metadata, log *logger.Logger) (*RecorderMiddleware, error) {
+	samplePercent, err := meta.ParseIntWithRange("record_sample_percent", defaultRecordSamplePercent, 1, 100)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recorder sample percent value, %w", err)
+	}
+	maxSize, err := meta.ParseIntWithRange("record_max_size_mb", defaultRecordMaxSizeMB, 1, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recorder max size value, %w", err)
+	}
+	maxBackups, err := meta.ParseIntWithRange("record_max_backups", defaultRecordMaxBackups, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recorder max backups value, %w", err)
+	}
+	r := &RecorderMiddleware{
+		binding:       binding,
+		samplePercent: samplePercent,
+		redactKeys:    parseStringSet(meta.ParseString("record_redact_keys", "")),
+		log:           log,
+	}
+	filename := meta.ParseString("record_file", "")
+	if filename != "" {
+		r.writer = &logger.LogRotator{
+			Ctx:        context.Background(),
+			Filename:   filename,
+			MaxSize:    maxSize,
+			MaxBackups: maxBackups,
+			Compress:   meta.ParseBool("record_compress", false),
+		}
+	}
+	return r, nil
+}
+
+func (r *RecorderMiddleware) enabled() bool {
+	return r.writer != nil
+}
+
+func (r *RecorderMiddleware) sampled() bool {
+	return r.samplePercent >= 100 || rand.Intn(100) < r.samplePercent
+}
+
+func (r *RecorderMiddleware) redact(metadata types.Metadata) types.Metadata {
+	if metadata == nil {
+		return nil
+	}
+	redacted := types.NewMetadata()
+	for key, value := range metadata {
+		if r.redactKeys[key] {
+			value = recordRedactedValue
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+func (r *RecorderMiddleware) record(start time.Time, request *types.Request, resp *types.Response, err error) {
+	rec := &Record{
+		Binding:             r.binding,
+		Time:                start.UTC(),
+		LatencyMilliseconds: time.Since(start).Milliseconds(),
+	}
+	if request != nil {
+		rec.Request = &types.Request{
+			Metadata: r.redact(request.Metadata),
+			Data:     request.Data,
+		}
+	}
+	if resp != nil {
+		rec.Response = &types.Response{
+			Metadata: r.redact(resp.Metadata),
+			Data:     resp.Data,
+			IsError:  resp.IsError,
+			Error:    resp.Error,
+		}
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	line, marshalErr := json.Marshal(rec)
+	if marshalErr != nil {
+		r.log.Errorf("error marshaling request record, %s", marshalErr.Error())
+		return
+	}
+	if _, writeErr := r.writer.Write(append(line, '\n')); writeErr != nil {
+		r.log.Errorf("error writing request record, %s", writeErr.Error())
+	}
+}
+
+func (r *RecorderMiddleware) Close() error {
+	if r.writer == nil {
+		return nil
+	}
+	return r.writer.Close()
+}
+
+// ReadRecords reads the records of a recording file
+func ReadRecords(reader io.Reader) ([]*Record, error) {
+	var records []*Record
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordLineBytes)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		rec := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), rec); err != nil {
+			return nil, fmt.Errorf("invalid record at line %d, %w", line, err)
+		}
+		if rec.Request == nil {
+			return nil, fmt.Errorf("invalid record at line %d, no request found", line)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading records, %w", err)
+	}
+	return records, nil
+}
@@ -6,9 +6,24 @@ import (
 	"github.com/kubemq-hub/kubemq-targets/pkg/retry"
 	"github.com/kubemq-hub/kubemq-targets/types"
 	"math"
+	"strings"
 	"time"
 )
 
+const (
+	defaultRetryErrorClasses         = "transient,throttled,unknown"
+	defaultRetryAfterMaxMilliseconds = 60000
+)
+
+var retryErrorClasses = []types.ErrorClass{
+	types.ErrorClassUnknown,
+	types.ErrorClassInvalidRequest,
+	types.ErrorClassTransient,
+	types.ErrorClassThrottled,
+	types.ErrorClassNotFound,
+	types.ErrorClassConflict,
+}
+
 var delayTypeMap = map[string]string{
 	"back-off": "back-off",
 	"fixed":    "fixed",
@@ -17,7 +32,10 @@ var delayTypeMap = map[string]string{
 }
 
 type RetryMiddleware struct {
-	opts []retry.Option
+	opts          []retry.Option
+	classes       map[types.ErrorClass]bool
+	classMaxDelay map[types.ErrorClass]time.Duration
+	maxRetryAfter time.Duration
 }
 
 func parseRetryOptions(meta types.Metadata) ([]retry.Option, error) {
@@ -65,7 +83,79 @@ func NewRetryMiddleware(meta types.Metadata, log *logger.Logger) (*RetryMiddlewa
 			log.Errorf("retry %d failed, error: %s", n, err.Error())
 		}))
 	}
-	return &RetryMiddleware{
-		opts: opts,
-	}, nil
+	r := &RetryMiddleware{
+		opts:          opts,
+		classes:       map[types.ErrorClass]bool{},
+		classMaxDelay: map[types.ErrorClass]time.Duration{},
+	}
+	if err := r.parseErrorClasses(meta); err != nil {
+		return nil, fmt.Errorf("error parsing retry options, %w", err)
+	}
+	r.opts = append(r.opts, retry.ErrorDelay(r.errorDelay))
+	return r, nil
+}
+
+func (r *RetryMiddleware) parseErrorClasses(meta types.Metadata) error {
+	for _, class := range strings.Split(meta.ParseString("retry_error_classes", defaultRetryErrorClasses), ",") {
+		class = strings.TrimSpace(class)
+		if class == "" {
+			continue
+		}
+		if !isRetryErrorClass(types.ErrorClass(class)) {
+			return fmt.Errorf("invalid retry error class value %s", class)
+		}
+		r.classes[types.ErrorClass(class)] = true
+	}
+	for _, class := range retryErrorClasses {
+		delayMilliseconds, err := meta.ParseIntWithRange(fmt.Sprintf("retry_%s_delay_milliseconds", class), 0, 0, math.MaxInt32)
+		if err != nil {
+			return fmt.Errorf("invalid retry %s delay milliseconds value", class)
+		}
+		if delayMilliseconds > 0 {
+			r.classMaxDelay[class] = time.Duration(delayMilliseconds) * time.Millisecond
+		}
+	}
+	maxRetryAfter, err := meta.ParseIntWithRange("retry_after_max_milliseconds", defaultRetryAfterMaxMilliseconds, 0, math.MaxInt32)
+	if err != nil {
+		return fmt.Errorf("invalid retry after max milliseconds value")
+	}
+	r.maxRetryAfter = time.Duration(maxRetryAfter) * time.Millisecond
+	return nil
+}
+
+func isRetryErrorClass(class types.ErrorClass) bool {
+	for _, c := range retryErrorClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// retryErrorClass returns the class of a target error, unclassified timeout and throttling errors are classified as transient and throttled
+func retryErrorClass(err error) types.ErrorClass {
+	class := types.ErrorClassOf(err)
+	if class != types.ErrorClassUnknown {
+		return class
+	}
+	if IsTimeoutError(err) {
+		return types.ErrorClassTransient
+	}
+	if IsThrottlingError(nil, err) {
+		return types.ErrorClassThrottled
+	}
+	return types.ErrorClassUnknown
+}
+
+func (r *RetryMiddleware) retryable(err error) bool {
+	return retry.IsRecoverable(err) && r.classes[retryErrorClass(err)]
+}
+
+// errorDelay returns the delay bounds of the error class, the retry after hint of the error, up to the max retry after, is the min delay
+func (r *RetryMiddleware) errorDelay(err error) (time.Duration, time.Duration) {
+	minDelay := types.RetryAfterOf(err)
+	if minDelay > r.maxRetryAfter {
+		minDelay = r.maxRetryAfter
+	}
+	return minDelay, r.classMaxDelay[retryErrorClass(err)]
 }
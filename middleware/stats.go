@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+type requestStatsKey struct{}
+
+// requestStats collects per request values reported by inner middlewares to the metrics middleware
+type requestStats struct {
+	retries       int64
+	rateLimitWait int64
+	cacheHits     int64
+	cacheMisses   int64
+}
+
+func withRequestStats(ctx context.Context) (context.Context, *requestStats) {
+	stats := &requestStats{}
+	return context.WithValue(ctx, requestStatsKey{}, stats), stats
+}
+
+func requestStatsFrom(ctx context.Context) *requestStats {
+	stats, _ := ctx.Value(requestStatsKey{}).(*requestStats)
+	return stats
+}
+
+func (s *requestStats) addRetries(n int64) {
+	if s != nil {
+		atomic.AddInt64(&s.retries, n)
+	}
+}
+
+func (s *requestStats) addRateLimitWait(d time.Duration) {
+	if s != nil {
+		atomic.AddInt64(&s.rateLimitWait, int64(d))
+	}
+}
+
+func (s *requestStats) addCacheHit() {
+	if s != nil {
+		atomic.AddInt64(&s.cacheHits, 1)
+	}
+}
+
+func (s *requestStats) addCacheMiss() {
+	if s != nil {
+		atomic.AddInt64(&s.cacheMisses, 1)
+	}
+}
+
+func (s *requestStats) getRetries() int64 {
+	return atomic.LoadInt64(&s.retries)
+}
+
+func (s *requestStats) getRateLimitWait() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.rateLimitWait))
+}
+
+func (s *requestStats) getCacheHits() int64 {
+	return atomic.LoadInt64(&s.cacheHits)
+}
+
+func (s *requestStats) getCacheMisses() int64 {
+	return atomic.LoadInt64(&s.cacheMisses)
+}
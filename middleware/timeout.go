@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/pkg/retry"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultTimeoutMetadataKey = "request_timeout_milliseconds"
+	defaultTimeoutMaxPending  = 100
+)
+
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("request timeout after %s", e.Timeout.String())
+}
+
+// IsTimeoutError reports whether err, or the last attempt error of a retry error, is a TimeoutError
+func IsTimeoutError(err error) bool {
+	var timeoutErr *TimeoutError
+	return errors.As(lastError(err), &timeoutErr)
+}
+
+// lastError returns the last attempt error of a retry error, other errors are returned as is
+func lastError(err error) error {
+	var retryErr retry.Error
+	if errors.As(err, &retryErr) {
+		for i := len(retryErr) - 1; i >= 0; i-- {
+			if retryErr[i] != nil {
+				return retryErr[i]
+			}
+		}
+	}
+	return err
+}
+
+type TimeoutMiddleware struct {
+	timeout     time.Duration
+	metadataKey string
+	maxPending  int64
+	// pending are the target executions which timed out and still run, targets which ignore the context cancellation
+	// keep running after the timeout error is returned
+	pending int64
+}
+
+func NewTimeoutMiddleware(meta types.Metadata) (*TimeoutMiddleware, error) {
+	timeout, err := meta.ParseIntWithRange("timeout_milliseconds", 0, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout milliseconds value, %w", err)
+	}
+	maxPending, err := meta.ParseIntWithRange("timeout_max_pending", defaultTimeoutMaxPending, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout max pending value, %w", err)
+	}
+	return &TimeoutMiddleware{
+		timeout:     time.Duration(timeout) * time.Millisecond,
+		metadataKey: meta.ParseString("timeout_metadata_key", defaultTimeoutMetadataKey),
+		maxPending:  int64(maxPending),
+	}, nil
+}
+
+func (tm *TimeoutMiddleware) requestTimeout(request *types.Request) (time.Duration, error) {
+	if request == nil || request.Metadata == nil {
+		return tm.timeout, nil
+	}
+	val, ok := request.Metadata[tm.metadataKey]
+	if !ok || val == "" {
+		return tm.timeout, nil
+	}
+	timeout, err := strconv.Atoi(val)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s metadata value, %s, must be a positive number of milliseconds", tm.metadataKey, val)
+	}
+	return time.Duration(timeout) * time.Millisecond, nil
+}
+
+// acquire reports whether a request can be executed, requests are rejected while max pending timed out executions still
+// run, a max pending of 0 does not limit them
+func (tm *TimeoutMiddleware) acquire() error {
+	if tm.maxPending == 0 {
+		return nil
+	}
+	if pending := atomic.LoadInt64(&tm.pending); pending >= tm.maxPending {
+		return types.NewTransientError(fmt.Errorf("%d timed out target executions are still running", pending))
+	}
+	return nil
+}
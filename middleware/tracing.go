@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"github.com/kubemq-hub/kubemq-targets/pkg/tracing"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceSource starts the root span of a binding request, continuing any trace context carried by the request metadata
+func TraceSource(binding, sourceKind string) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			if request != nil {
+				ctx = tracing.Extract(ctx, request.Metadata)
+			}
+			ctx, span := tracing.Tracer().Start(ctx, "source "+sourceKind,
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("binding", binding),
+					attribute.String("source.kind", sourceKind),
+				))
+			defer span.End()
+			resp, err := df.Do(ctx, request)
+			endSpan(span, resp, err)
+			return resp, err
+		})
+	}
+}
+
+// Trace wraps a middleware function with a span named after the middleware
+func Trace(name string, mf MiddlewareFunc) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		next := mf(df)
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			ctx, span := tracing.Tracer().Start(ctx, "middleware "+name)
+			defer span.End()
+			resp, err := next.Do(ctx, request)
+			endSpan(span, resp, err)
+			return resp, err
+		})
+	}
+}
+
+// TraceTarget wraps the target Do call with a client span
+func TraceTarget(targetKind string) MiddlewareFunc {
+	return func(df Middleware) Middleware {
+		return DoFunc(func(ctx context.Context, request *types.Request) (*types.Response, error) {
+			attrs := []attribute.KeyValue{attribute.String("target.kind", targetKind)}
+			if request != nil {
+				if method := request.Metadata.Get("method"); method != "" {
+					attrs = append(attrs, attribute.String("target.method", method))
+				}
+			}
+			ctx, span := tracing.Tracer().Start(ctx, "target "+targetKind,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...))
+			defer span.End()
+			resp, err := df.Do(ctx, request)
+			endSpan(span, resp, err)
+			return resp, err
+		})
+	}
+}
+
+func endSpan(span trace.Span, resp *types.Response, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	if resp != nil && resp.IsError {
+		span.SetStatus(codes.Error, resp.Error)
+	}
+}
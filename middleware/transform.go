@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/pkg/expression"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"sort"
+	"strings"
+)
+
+type transformer struct {
+	metadata     map[string]*expression.Expression
+	metadataKeys []string
+	drop         []string
+	data         *expression.Expression
+}
+
+func newTransformer(meta types.Metadata, prefix string) (*transformer, error) {
+	t := &transformer{
+		metadata: map[string]*expression.Expression{},
+	}
+	metadataTemplates, err := meta.MustParseJsonMap(prefix + "_metadata")
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s metadata value, %w", prefix, err)
+	}
+	for key, text := range metadataTemplates {
+		exp, err := expression.New(key, text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s metadata template for key %s, %w", prefix, key, err)
+		}
+		t.metadata[key] = exp
+		t.metadataKeys = append(t.metadataKeys, key)
+	}
+	sort.Strings(t.metadataKeys)
+	if drop := meta.ParseString(prefix+"_drop_metadata", ""); drop != "" {
+		for _, key := range strings.Split(drop, ",") {
+			t.drop = append(t.drop, strings.TrimSpace(key))
+		}
+	}
+	if text := meta.ParseString(prefix+"_data", ""); text != "" {
+		t.data, err = expression.New("data", text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s data template, %w", prefix, err)
+		}
+	}
+	return t, nil
+}
+
+func (t *transformer) isEmpty() bool {
+	return len(t.metadata) == 0 && len(t.drop) == 0 && t.data == nil
+}
+
+func (t *transformer) transform(metadata types.Metadata, data []byte) (types.Metadata, []byte, error) {
+	if t.isEmpty() {
+		return metadata, data, nil
+	}
+	ec := expression.NewContext(metadata, data)
+	newMetadata := types.NewMetadata()
+	for key, value := range metadata {
+		newMetadata.Set(key, value)
+	}
+	for _, key := range t.metadataKeys {
+		value, err := t.metadata[key].Execute(ec)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error transforming metadata key %s, %w", key, err)
+		}
+		newMetadata.Set(key, value)
+	}
+	for _, key := range t.drop {
+		delete(newMetadata, key)
+	}
+	newData := data
+	if t.data != nil {
+		value, err := t.data.Execute(ec)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error transforming data, %w", err)
+		}
+		newData = []byte(value)
+	}
+	return newMetadata, newData, nil
+}
+
+type TransformMiddleware struct {
+	request  *transformer
+	response *transformer
+}
+
+func NewTransformMiddleware(meta types.Metadata) (*TransformMiddleware, error) {
+	request, err := newTransformer(meta, "transform_request")
+	if err != nil {
+		return nil, err
+	}
+	response, err := newTransformer(meta, "transform_response")
+	if err != nil {
+		return nil, err
+	}
+	return &TransformMiddleware{
+		request:  request,
+		response: response,
+	}, nil
+}
+
+func (tm *TransformMiddleware) transformRequest(request *types.Request) (*types.Request, error) {
+	if request == nil {
+		return nil, nil
+	}
+	metadata, data, err := tm.request.transform(request.Metadata, request.Data)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewRequest().SetMetadata(metadata).SetData(data), nil
+}
+
+func (tm *TransformMiddleware) transformResponse(response *types.Response) (*types.Response, error) {
+	if response == nil {
+		return nil, nil
+	}
+	metadata, data, err := tm.response.transform(response.Metadata, response.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &types.Response{
+		Metadata: metadata,
+		Data:     data,
+		IsError:  response.IsError,
+		Error:    response.Error,
+	}, nil
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"github.com/xeipuuv/gojsonschema"
+	"io/ioutil"
+	"strings"
+)
+
+// Violation is a single request field which failed schema validation
+type Violation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// ValidationError lists all the violations of a rejected request
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	b, _ := json.Marshal(e)
+	return fmt.Sprintf("request validation failed, %s", string(b))
+}
+
+type ValidationMiddleware struct {
+	metadataSchemas []*gojsonschema.Schema
+	dataSchema      *gojsonschema.Schema
+}
+
+// NewValidationMiddleware creates a validation middleware, connector is used to derive a metadata schema when validation_connector_schema is set
+func NewValidationMiddleware(meta types.Metadata, connector *common.Connector) (*ValidationMiddleware, error) {
+	v := &ValidationMiddleware{}
+	metadataSchema, err := loadSchema(meta, "validation_metadata_schema")
+	if err != nil {
+		return nil, fmt.Errorf("invalid validation metadata schema, %w", err)
+	}
+	if metadataSchema != nil {
+		v.metadataSchemas = append(v.metadataSchemas, metadataSchema)
+	}
+	if meta.ParseBool("validation_connector_schema", false) {
+		if connector == nil {
+			return nil, fmt.Errorf("invalid validation connector schema, no target connector found")
+		}
+		connectorSchema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(ConnectorMetadataSchema(connector)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid validation connector schema, %w", err)
+		}
+		v.metadataSchemas = append(v.metadataSchemas, connectorSchema)
+	}
+	v.dataSchema, err = loadSchema(meta, "validation_data_schema")
+	if err != nil {
+		return nil, fmt.Errorf("invalid validation data schema, %w", err)
+	}
+	return v, nil
+}
+
+// loadSchema loads an inline schema from key, or a schema file from key_file, returns nil when none is set
+func loadSchema(meta types.Metadata, key string) (*gojsonschema.Schema, error) {
+	inline := meta.ParseString(key, "")
+	filename := meta.ParseString(key+"_file", "")
+	switch {
+	case inline != "" && filename != "":
+		return nil, fmt.Errorf("%s and %s_file cannot be set together", key, key)
+	case inline != "":
+		return gojsonschema.NewSchema(gojsonschema.NewStringLoader(inline))
+	case filename != "":
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("error reading schema file, %w", err)
+		}
+		return gojsonschema.NewSchema(gojsonschema.NewBytesLoader(data))
+	default:
+		return nil, nil
+	}
+}
+
+// ConnectorMetadataSchema derives a request metadata schema from the connector metadata definitions.
+// Method is required and limited to the connector methods, values of other keys are checked by kind and options only,
+// as connector definitions do not declare which keys are required per method.
+func ConnectorMetadataSchema(connector *common.Connector) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []interface{}
+	for _, md := range connector.Metadata {
+		property := map[string]interface{}{
+			"type": "string",
+		}
+		switch {
+		case len(md.Options) > 0:
+			var options []interface{}
+			hasEmpty := false
+			for _, option := range md.Options {
+				options = append(options, option)
+				hasEmpty = hasEmpty || option == ""
+			}
+			if md.Name != "method" && !hasEmpty {
+				options = append(options, "")
+			}
+			property["enum"] = options
+		case md.Kind == "int":
+			property["pattern"] = "^(-?[0-9]+)?$"
+		case md.Kind == "bool":
+			property["pattern"] = "^(1|t|T|TRUE|true|True|0|f|F|FALSE|false|False)?$"
+		}
+		properties[md.Name] = property
+		if md.Name == "method" && md.Must {
+			required = append(required, md.Name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (v *ValidationMiddleware) enabled() bool {
+	return len(v.metadataSchemas) > 0 || v.dataSchema != nil
+}
+
+func (v *ValidationMiddleware) validate(request *types.Request) error {
+	var violations []Violation
+	metadata := map[string]interface{}{}
+	for key, value := range request.Metadata {
+		metadata[key] = value
+	}
+	for _, schema := range v.metadataSchemas {
+		result, err := schema.Validate(gojsonschema.NewGoLoader(metadata))
+		if err != nil {
+			violations = append(violations, Violation{Field: "metadata", Description: err.Error()})
+			continue
+		}
+		violations = append(violations, resultViolations("metadata", result)...)
+	}
+	if v.dataSchema != nil {
+		result, err := v.dataSchema.Validate(gojsonschema.NewBytesLoader(request.Data))
+		if err != nil {
+			violations = append(violations, Violation{Field: "data", Description: fmt.Sprintf("invalid json data, %s", err.Error())})
+		} else {
+			violations = append(violations, resultViolations("data", result)...)
+		}
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func resultViolations(root string, result *gojsonschema.Result) []Violation {
+	var violations []Violation
+	for _, resultErr := range result.Errors() {
+		field := root
+		if resultErr.Field() != gojsonschema.STRING_CONTEXT_ROOT {
+			field = root + "." + strings.TrimPrefix(resultErr.Field(), gojsonschema.STRING_CONTEXT_ROOT+".")
+		}
+		violations = append(violations, Violation{Field: field, Description: resultErr.Description()})
+	}
+	return violations
+}
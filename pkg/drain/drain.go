@@ -0,0 +1,64 @@
+package drain
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrDraining = errors.New("source is draining, request rejected")
+
+// Tracker counts in-flight messages of a source and rejects new messages once draining has started.
+// The zero value is ready to use.
+type Tracker struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	draining bool
+}
+
+// Acquire registers a new in-flight message, returns false when the source is draining
+func (t *Tracker) Acquire() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.wg.Add(1)
+	return true
+}
+
+// Release marks an in-flight message as done
+func (t *Tracker) Release() {
+	t.wg.Done()
+}
+
+// Stop rejects new messages without waiting for in-flight messages
+func (t *Tracker) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.draining = true
+}
+
+func (t *Tracker) IsDraining() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining
+}
+
+// Drain stops accepting new messages and waits for all in-flight messages or until the context is done
+func (t *Tracker) Drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,58 @@
+package drain
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTracker_Drain(t *testing.T) {
+	tests := []struct {
+		name     string
+		inFlight time.Duration
+		deadline time.Duration
+		wantErr  bool
+	}{
+		{
+			name:     "no in-flight messages",
+			inFlight: 0,
+			deadline: 100 * time.Millisecond,
+			wantErr:  false,
+		},
+		{
+			name:     "in-flight messages finished before deadline",
+			inFlight: 50 * time.Millisecond,
+			deadline: time.Second,
+			wantErr:  false,
+		},
+		{
+			name:     "in-flight messages exceeded deadline",
+			inFlight: time.Second,
+			deadline: 50 * time.Millisecond,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := &Tracker{}
+			if tt.inFlight > 0 {
+				require.True(t, tracker.Acquire())
+				go func() {
+					time.Sleep(tt.inFlight)
+					tracker.Release()
+				}()
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), tt.deadline)
+			defer cancel()
+			err := tracker.Drain(ctx)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.True(t, tracker.IsDraining())
+			require.False(t, tracker.Acquire())
+		})
+	}
+}
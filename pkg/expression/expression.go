@@ -0,0 +1,139 @@
+package expression
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"io"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// missingValueFunc is appended to the output actions of expressions, so missing data values are printed as empty
+// strings and not as <no value>
+const missingValueFunc = "_missingValue"
+
+var funcs = template.FuncMap{
+	"toJson": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	},
+	"default": func(def, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"trim":     strings.TrimSpace,
+	"replace":  strings.ReplaceAll,
+	"contains": strings.Contains,
+	missingValueFunc: func(v interface{}) interface{} {
+		if v == nil {
+			return ""
+		}
+		return v
+	},
+}
+
+// Context is the value an expression is evaluated against, .Metadata and .Data
+type Context struct {
+	Metadata types.Metadata
+	Data     interface{}
+}
+
+func NewContext(metadata types.Metadata, data []byte) Context {
+	return Context{
+		Metadata: metadata,
+		Data:     parseData(data),
+	}
+}
+
+// parseData decodes json data with numbers as json.Number, so numbers are printed as sent, 1234567 and not 1.234567e+06
+func parseData(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return string(data)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return string(data)
+	}
+	return v
+}
+
+type Expression struct {
+	tmpl *template.Template
+}
+
+func New(name, text string) (*Expression, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			appendMissingValue(t.Tree, t.Tree.Root)
+		}
+	}
+	return &Expression{
+		tmpl: tmpl,
+	}, nil
+}
+
+// appendMissingValue appends the missing value function to the pipelines of the output actions of node
+func appendMissingValue(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			appendMissingValue(tree, child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(missingValueFunc).SetTree(tree).SetPos(n.Pos)},
+		})
+	case *parse.IfNode:
+		appendMissingValue(tree, n.List)
+		appendMissingValue(tree, n.ElseList)
+	case *parse.RangeNode:
+		appendMissingValue(tree, n.List)
+		appendMissingValue(tree, n.ElseList)
+	case *parse.WithNode:
+		appendMissingValue(tree, n.List)
+		appendMissingValue(tree, n.ElseList)
+	}
+}
+
+func (e *Expression) Execute(ctx Context) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := e.tmpl.Execute(buf, ctx); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Match evaluates the expression as a predicate, only a "true" result is considered a match
+func (e *Expression) Match(ctx Context) (bool, error) {
+	result, err := e.Execute(ctx)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(result) == "true", nil
+}
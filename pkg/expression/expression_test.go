@@ -0,0 +1,69 @@
+package expression
+
+import (
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExpression_Execute(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		metadata types.Metadata
+		data     string
+		want     string
+	}{
+		{
+			name:     "metadata value",
+			text:     "{{.Metadata.method | upper}}",
+			metadata: types.Metadata{"method": "get"},
+			want:     "GET",
+		},
+		{
+			name: "missing values",
+			text: "[{{.Metadata.method}}][{{.Data.missing}}][{{if .Data.missing}}x{{end}}]",
+			data: `{"id":1}`,
+			want: "[][][]",
+		},
+		{
+			name: "missing values in range",
+			text: "{{range .Data.items}}[{{.name}}]{{end}}",
+			data: `{"items":[{"name":"a"},{}]}`,
+			want: "[a][]",
+		},
+		{
+			name: "no value text in data",
+			text: "{{.Data.text}}",
+			data: `{"text":"<no value>"}`,
+			want: "<no value>",
+		},
+		{
+			name: "default value",
+			text: `{{default "none" .Data.missing}}`,
+			data: `{"id":1}`,
+			want: "none",
+		},
+		{
+			name: "numbers",
+			text: "{{.Data.id}} {{.Data.price}} {{toJson .Data}}",
+			data: `{"id":1234567,"price":10.50}`,
+			want: `1234567 10.50 {"id":1234567,"price":10.50}`,
+		},
+		{
+			name: "not json data",
+			text: "{{.Data}}",
+			data: `{"id":1} trailing`,
+			want: `{"id":1} trailing`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.name, tt.text)
+			require.NoError(t, err)
+			got, err := e.Execute(NewContext(tt.metadata, []byte(tt.data)))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
+	"sync"
 )
 
 var labels = []string{"binding", "source_kind", "target_kind"}
@@ -15,6 +16,15 @@ type Exporter struct {
 	requestsVolumeCollector  *promCounterMetric
 	responsesVolumeCollector *promCounterMetric
 	errorsCollector          *promCounterMetric
+	timeoutsCollector        *promCounterMetric
+	circuitBreakerCollector  *promGaugeMetric
+	retriesCollector         *promCounterMetric
+	rateLimitWaitCollector   *promCounterMetric
+	inFlightCollector        *promGaugeMetric
+	errorClassesCollector    *promCounterMetric
+	cacheHitsCollector       *promCounterMetric
+	cacheMissesCollector     *promCounterMetric
+	latencyCollectors        sync.Map
 }
 
 func (e *Exporter) PrometheusHandler() http.Handler {
@@ -29,6 +39,15 @@ func NewExporter() (*Exporter, error) {
 		requestsVolumeCollector:  nil,
 		responsesVolumeCollector: nil,
 		errorsCollector:          nil,
+		timeoutsCollector:        nil,
+		circuitBreakerCollector:  nil,
+		retriesCollector:         nil,
+		rateLimitWaitCollector:   nil,
+		inFlightCollector:        nil,
+		errorClassesCollector:    nil,
+		cacheHitsCollector:       nil,
+		cacheMissesCollector:     nil,
+		latencyCollectors:        sync.Map{},
 	}
 	if err := e.initPromMetrics(); err != nil {
 		return nil, err
@@ -67,28 +86,73 @@ func (e *Exporter) initPromMetrics() error {
 		"counts error requests per binding,source and target types",
 		labels...,
 	)
-
-	err := prometheus.Register(e.requestsCollector.metric)
-	if err != nil {
-		return err
-	}
-	err = prometheus.Register(e.responsesCollector.metric)
-	if err != nil {
-		return err
-	}
-	err = prometheus.Register(e.requestsVolumeCollector.metric)
-	if err != nil {
-		return err
-	}
-	err = prometheus.Register(e.responsesVolumeCollector.metric)
-	if err != nil {
-		return err
-	}
-	err = prometheus.Register(e.errorsCollector.metric)
-	if err != nil {
-		return err
+	e.timeoutsCollector = newPromCounterMetric(
+		"timeouts",
+		"count",
+		"counts timed out requests per binding,source and target types",
+		labels...,
+	)
+	e.circuitBreakerCollector = newPromGaugeMetric(
+		"circuit_breaker",
+		"state",
+		"circuit breaker state per binding,source and target types (0 - closed, 1 - half-open, 2 - open)",
+		labels...,
+	)
+	e.retriesCollector = newPromCounterMetric(
+		"retries",
+		"count",
+		"counts target execution retries per binding,source and target types",
+		labels...,
+	)
+	e.rateLimitWaitCollector = newPromCounterMetric(
+		"rate_limiter",
+		"wait_seconds",
+		"sum rate limiter wait time in seconds per binding,source and target types",
+		labels...,
+	)
+	e.inFlightCollector = newPromGaugeMetric(
+		"requests",
+		"in_flight",
+		"current in flight requests per binding,source and target types",
+		labels...,
+	)
+	e.errorClassesCollector = newPromCounterMetric(
+		"errors",
+		"by_class",
+		"counts error requests per binding,source and target types and error class",
+		append(labels, "class")...,
+	)
+	e.cacheHitsCollector = newPromCounterMetric(
+		"cache",
+		"hits",
+		"counts response cache hits per binding,source and target types",
+		labels...,
+	)
+	e.cacheMissesCollector = newPromCounterMetric(
+		"cache",
+		"misses",
+		"counts response cache misses per binding,source and target types",
+		labels...,
+	)
+	for _, collector := range []prometheus.Collector{
+		e.requestsCollector.metric,
+		e.responsesCollector.metric,
+		e.requestsVolumeCollector.metric,
+		e.responsesVolumeCollector.metric,
+		e.errorsCollector.metric,
+		e.timeoutsCollector.metric,
+		e.circuitBreakerCollector.metric,
+		e.retriesCollector.metric,
+		e.rateLimitWaitCollector.metric,
+		e.inFlightCollector.metric,
+		e.errorClassesCollector.metric,
+		e.cacheHitsCollector.metric,
+		e.cacheMissesCollector.metric,
+	} {
+		if err := prometheus.Register(collector); err != nil {
+			return err
+		}
 	}
-
 	return nil
 }
 
@@ -99,5 +163,57 @@ func (e *Exporter) Report(m *Report) {
 	e.responsesCollector.add(m.ResponseCount, lbs)
 	e.responsesVolumeCollector.add(m.ResponseVolume, lbs)
 	e.errorsCollector.add(m.ErrorsCount, lbs)
+	e.timeoutsCollector.add(m.TimeoutsCount, lbs)
+	e.retriesCollector.add(m.RetriesCount, lbs)
+	e.rateLimitWaitCollector.add(m.RateLimitWaitSeconds, lbs)
+	e.cacheHitsCollector.add(m.CacheHits, lbs)
+	e.cacheMissesCollector.add(m.CacheMisses, lbs)
+	if m.InFlight != 0 {
+		e.inFlightCollector.add(m.InFlight, lbs)
+	}
+	for class, count := range m.ErrorClasses {
+		classLabels := m.labels()
+		classLabels["class"] = class
+		e.errorClassesCollector.add(count, classLabels)
+	}
 	e.Store.Add(m)
 }
+
+func (e *Exporter) ReportCircuitBreakerState(m *Report, state float64) {
+	e.circuitBreakerCollector.set(state, m.labels())
+}
+
+// RegisterLatency registers the binding latency histogram with its own buckets, replacing a previous registration
+func (e *Exporter) RegisterLatency(m *Report, buckets []float64) error {
+	e.UnregisterLatency(m.Binding)
+	h := newPromHistogramMetric(
+		"requests",
+		"latency_seconds",
+		"requests latency in seconds per binding,source and target types and method",
+		buckets,
+		m.labels(),
+		"method",
+	)
+	if err := prometheus.Register(h.metric); err != nil {
+		return err
+	}
+	e.latencyCollectors.Store(m.Binding, h)
+	return nil
+}
+
+func (e *Exporter) UnregisterLatency(binding string) {
+	val, ok := e.latencyCollectors.Load(binding)
+	if !ok {
+		return
+	}
+	prometheus.Unregister(val.(*promHistogramMetric).metric)
+	e.latencyCollectors.Delete(binding)
+}
+
+func (e *Exporter) ObserveLatency(m *Report, method string, seconds float64) {
+	val, ok := e.latencyCollectors.Load(m.Binding)
+	if !ok {
+		return
+	}
+	val.(*promHistogramMetric).observe(seconds, prometheus.Labels{"method": method})
+}
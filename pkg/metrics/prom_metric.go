@@ -28,3 +28,52 @@ func (c *promCounterMetric) add(value float64, labels prometheus.Labels) {
 	}
 
 }
+
+type promGaugeMetric struct {
+	metric *prometheus.GaugeVec
+}
+
+func newPromGaugeMetric(subsystem, name, help string, labels ...string) *promGaugeMetric {
+	opts := prometheus.GaugeOpts{
+		Namespace:   "kubemq_targets",
+		Subsystem:   subsystem,
+		Name:        name,
+		Help:        help,
+		ConstLabels: nil,
+	}
+
+	g := &promGaugeMetric{}
+	g.metric = prometheus.NewGaugeVec(opts, labels)
+	return g
+}
+
+func (g *promGaugeMetric) set(value float64, labels prometheus.Labels) {
+	g.metric.With(labels).Set(value)
+}
+
+func (g *promGaugeMetric) add(value float64, labels prometheus.Labels) {
+	g.metric.With(labels).Add(value)
+}
+
+type promHistogramMetric struct {
+	metric *prometheus.HistogramVec
+}
+
+func newPromHistogramMetric(subsystem, name, help string, buckets []float64, constLabels prometheus.Labels, labels ...string) *promHistogramMetric {
+	opts := prometheus.HistogramOpts{
+		Namespace:   "kubemq_targets",
+		Subsystem:   subsystem,
+		Name:        name,
+		Help:        help,
+		ConstLabels: constLabels,
+		Buckets:     buckets,
+	}
+
+	h := &promHistogramMetric{}
+	h.metric = prometheus.NewHistogramVec(opts, labels)
+	return h
+}
+
+func (h *promHistogramMetric) observe(value float64, labels prometheus.Labels) {
+	h.metric.With(labels).Observe(value)
+}
@@ -5,15 +5,25 @@ import (
 )
 
 type Report struct {
-	Key            string  `json:"-"`
-	Binding        string  `json:"binding"`
-	SourceKind     string  `json:"source_kind"`
-	TargetKind     string  `json:"target_kind"`
-	RequestCount   float64 `json:"request_count"`
-	RequestVolume  float64 `json:"request_volume"`
-	ResponseCount  float64 `json:"response_count"`
-	ResponseVolume float64 `json:"response_volume"`
-	ErrorsCount    float64 `json:"errors_count"`
+	Key                  string             `json:"-"`
+	Binding              string             `json:"binding"`
+	SourceKind           string             `json:"source_kind"`
+	TargetKind           string             `json:"target_kind"`
+	RequestCount         float64            `json:"request_count"`
+	RequestVolume        float64            `json:"request_volume"`
+	ResponseCount        float64            `json:"response_count"`
+	ResponseVolume       float64            `json:"response_volume"`
+	ErrorsCount          float64            `json:"errors_count"`
+	TimeoutsCount        float64            `json:"timeouts_count"`
+	RetriesCount         float64            `json:"retries_count"`
+	RateLimitWaitSeconds float64            `json:"rate_limit_wait_seconds"`
+	CacheHits            float64            `json:"cache_hits"`
+	CacheMisses          float64            `json:"cache_misses"`
+	InFlight             float64            `json:"in_flight"`
+	LatencySecondsSum    float64            `json:"latency_seconds_sum"`
+	LatencyAvgSeconds    float64            `json:"latency_avg_seconds"`
+	LatencyMaxSeconds    float64            `json:"latency_max_seconds"`
+	ErrorClasses         map[string]float64 `json:"error_classes,omitempty"`
 }
 
 func (m *Report) labels() prometheus.Labels {
@@ -25,15 +35,31 @@ func (m *Report) labels() prometheus.Labels {
 }
 
 func (m *Report) Clone() *Report {
-	return &Report{
-		Key:            m.Key,
-		Binding:        m.Binding,
-		SourceKind:     m.SourceKind,
-		TargetKind:     m.TargetKind,
-		RequestCount:   m.RequestCount,
-		RequestVolume:  m.RequestVolume,
-		ResponseCount:  m.ResponseCount,
-		ResponseVolume: m.ResponseVolume,
-		ErrorsCount:    m.ErrorsCount,
+	r := &Report{
+		Key:                  m.Key,
+		Binding:              m.Binding,
+		SourceKind:           m.SourceKind,
+		TargetKind:           m.TargetKind,
+		RequestCount:         m.RequestCount,
+		RequestVolume:        m.RequestVolume,
+		ResponseCount:        m.ResponseCount,
+		ResponseVolume:       m.ResponseVolume,
+		ErrorsCount:          m.ErrorsCount,
+		TimeoutsCount:        m.TimeoutsCount,
+		RetriesCount:         m.RetriesCount,
+		RateLimitWaitSeconds: m.RateLimitWaitSeconds,
+		CacheHits:            m.CacheHits,
+		CacheMisses:          m.CacheMisses,
+		InFlight:             m.InFlight,
+		LatencySecondsSum:    m.LatencySecondsSum,
+		LatencyAvgSeconds:    m.LatencyAvgSeconds,
+		LatencyMaxSeconds:    m.LatencyMaxSeconds,
 	}
+	if m.ErrorClasses != nil {
+		r.ErrorClasses = map[string]float64{}
+		for class, count := range m.ErrorClasses {
+			r.ErrorClasses[class] = count
+		}
+	}
+	return r
 }
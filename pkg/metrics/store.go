@@ -3,6 +3,7 @@ package metrics
 import "sync"
 
 type Store struct {
+	sync.Mutex
 	store sync.Map
 }
 
@@ -12,31 +13,58 @@ func NewStore() *Store {
 	}
 }
 func (s *Store) Add(report *Report) {
+	s.Lock()
+	defer s.Unlock()
 	val, ok := s.store.Load(report.Key)
 	if ok {
 		loaded := val.(*Report)
 		loaded.ErrorsCount += report.ErrorsCount
+		loaded.TimeoutsCount += report.TimeoutsCount
 		loaded.ResponseVolume += report.ResponseVolume
 		loaded.ResponseCount += report.ResponseCount
 		loaded.RequestVolume += report.RequestVolume
 		loaded.RequestCount += report.RequestCount
+		loaded.RetriesCount += report.RetriesCount
+		loaded.RateLimitWaitSeconds += report.RateLimitWaitSeconds
+		loaded.CacheHits += report.CacheHits
+		loaded.CacheMisses += report.CacheMisses
+		loaded.InFlight += report.InFlight
+		loaded.LatencySecondsSum += report.LatencySecondsSum
+		if report.LatencyMaxSeconds > loaded.LatencyMaxSeconds {
+			loaded.LatencyMaxSeconds = report.LatencyMaxSeconds
+		}
+		for class, count := range report.ErrorClasses {
+			if loaded.ErrorClasses == nil {
+				loaded.ErrorClasses = map[string]float64{}
+			}
+			loaded.ErrorClasses[class] += count
+		}
+		if loaded.RequestCount > 0 {
+			loaded.LatencyAvgSeconds = loaded.LatencySecondsSum / loaded.RequestCount
+		}
 	} else {
 		s.store.Store(report.Key, report.Clone())
 	}
 }
 
+// Get returns a copy of the report of a key
 func (s *Store) Get(key string) *Report {
+	s.Lock()
+	defer s.Unlock()
 	val, ok := s.store.Load(key)
 	if ok {
-		return val.(*Report)
+		return val.(*Report).Clone()
 	}
 	return nil
 }
 
+// List returns copies of the stored reports
 func (s *Store) List() []*Report {
+	s.Lock()
+	defer s.Unlock()
 	var list []*Report
 	s.store.Range(func(key, value interface{}) bool {
-		list = append(list, value.(*Report))
+		list = append(list, value.(*Report).Clone())
 		return true
 	})
 	return list
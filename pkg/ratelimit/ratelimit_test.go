@@ -1,6 +1,7 @@
 package ratelimit_test
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
@@ -116,3 +117,71 @@ func job(rl ratelimit.Limiter, count *atomic.Int32, done <-chan struct{}) {
 		}
 	}
 }
+
+func TestTokenBucket(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     float64
+		burst    int
+		requests int
+		minTime  time.Duration
+		maxTime  time.Duration
+	}{
+		{
+			name:     "burst is not limited",
+			rate:     10,
+			burst:    10,
+			requests: 10,
+			minTime:  0,
+			maxTime:  50 * time.Millisecond,
+		},
+		{
+			name:     "requests over burst are limited",
+			rate:     20,
+			burst:    1,
+			requests: 5,
+			minTime:  190 * time.Millisecond,
+			maxTime:  300 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := ratelimit.NewTokenBucket(tt.rate, tt.burst)
+			start := time.Now()
+			for i := 0; i < tt.requests; i++ {
+				assert.NoError(t, tb.Wait(context.Background()))
+			}
+			elapsed := time.Since(start)
+			assert.GreaterOrEqual(t, int64(elapsed), int64(tt.minTime))
+			assert.LessOrEqual(t, int64(elapsed), int64(tt.maxTime))
+		})
+	}
+}
+
+func TestTokenBucket_WaitContext(t *testing.T) {
+	tb := ratelimit.NewTokenBucket(1, 1)
+	assert.NoError(t, tb.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.Equal(t, context.DeadlineExceeded, tb.Wait(ctx))
+	assert.Less(t, int64(time.Since(start)), int64(50*time.Millisecond))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	assert.Equal(t, context.Canceled, tb.Wait(ctx))
+}
+
+func TestTokenBucket_SetRate(t *testing.T) {
+	tb := ratelimit.NewTokenBucket(1, 1)
+	assert.NoError(t, tb.Wait(context.Background()))
+	tb.SetRate(100)
+	assert.Equal(t, float64(100), tb.Rate())
+	start := time.Now()
+	assert.NoError(t, tb.Wait(context.Background()))
+	assert.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucket is a context aware token bucket limiter allowing bursts of up to burst requests
+// and a rate which can be changed while in use
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a full token bucket with rate tokens per second
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// advance adds the tokens accumulated since the last call, must be called with mu held
+func (tb *TokenBucket) advance(now time.Time) {
+	if !tb.last.IsZero() {
+		elapsed := now.Sub(tb.last).Seconds()
+		if elapsed > 0 {
+			tb.tokens = math.Min(tb.burst, tb.tokens+elapsed*tb.rate)
+		}
+	}
+	tb.last = now
+}
+
+// reserve takes a token and returns how long to wait until it is available
+func (tb *TokenBucket) reserve() time.Duration {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.advance(time.Now())
+	tb.tokens--
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
+
+func (tb *TokenBucket) cancel() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.tokens = math.Min(tb.burst, tb.tokens+1)
+}
+
+// Wait blocks until a token is available or the context is done, the token is returned when the context is done first
+func (tb *TokenBucket) Wait(ctx context.Context) error {
+	wait := tb.reserve()
+	if wait == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		tb.cancel()
+		return context.DeadlineExceeded
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		tb.cancel()
+		return ctx.Err()
+	}
+}
+
+// SetRate changes the rate of the bucket, tokens accumulated so far are kept
+func (tb *TokenBucket) SetRate(rate float64) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.advance(time.Now())
+	tb.rate = rate
+}
+
+func (tb *TokenBucket) Rate() float64 {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return tb.rate
+}
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"math/rand"
 	"time"
 )
@@ -14,6 +15,10 @@ type OnRetryFunc func(n uint, err error)
 
 type DelayTypeFunc func(n uint, config *Config) time.Duration
 
+// Function signature of ErrorDelay function
+// min is a lower bound of the delay and max replaces the max delay when higher than 0
+type ErrorDelayFunc func(err error) (min, max time.Duration)
+
 type Config struct {
 	attempts      uint
 	delay         time.Duration
@@ -22,7 +27,9 @@ type Config struct {
 	onRetry       OnRetryFunc
 	retryIf       RetryIfFunc
 	delayType     DelayTypeFunc
+	errorDelay    ErrorDelayFunc
 	lastErrorOnly bool
+	context       context.Context
 }
 
 // Option represents an option for retry.
@@ -75,6 +82,22 @@ func DelayType(delayType DelayTypeFunc) Option {
 	}
 }
 
+// ErrorDelay sets the delay bounds per error, such as a retry after hint of the error
+// does not apply by default
+func ErrorDelay(errorDelay ErrorDelayFunc) Option {
+	return func(c *Config) {
+		c.errorDelay = errorDelay
+	}
+}
+
+// Context sets the context of the retries, retries stop when the context is done, also while waiting between retries
+// default is context.Background()
+func Context(ctx context.Context) Option {
+	return func(c *Config) {
+		c.context = ctx
+	}
+}
+
 // BackOffDelay is a DelayType which increases delay between consecutive retries
 func BackOffDelay(n uint, config *Config) time.Duration {
 	return config.delay * (1 << n)
@@ -4,6 +4,7 @@ forked from: https://github.com/avast/retry-go
 package retry
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -34,6 +35,7 @@ func Do(retryableFunc RetryableFunc, opts ...Option) error {
 		retryIf:       DefaultRetryIf,
 		delayType:     DefaultDelayType,
 		lastErrorOnly: DefaultLastErrorOnly,
+		context:       context.Background(),
 	}
 
 	//apply opts
@@ -67,10 +69,23 @@ func Do(retryableFunc RetryableFunc, opts ...Option) error {
 			}
 
 			delayTime := config.delayType(n, config)
-			if config.maxDelay > 0 && delayTime > config.maxDelay {
-				delayTime = config.maxDelay
+			minDelay, maxDelay := time.Duration(0), config.maxDelay
+			if config.errorDelay != nil {
+				var errMaxDelay time.Duration
+				minDelay, errMaxDelay = config.errorDelay(err)
+				if errMaxDelay > 0 {
+					maxDelay = errMaxDelay
+				}
+			}
+			if maxDelay > 0 && delayTime > maxDelay {
+				delayTime = maxDelay
+			}
+			if delayTime < minDelay {
+				delayTime = minDelay
+			}
+			if !sleep(config.context, delayTime) {
+				break
 			}
-			time.Sleep(delayTime)
 		} else {
 			return nil
 		}
@@ -87,6 +102,21 @@ func Do(retryableFunc RetryableFunc, opts ...Option) error {
 	return errorLog
 }
 
+// sleep waits for delay, it returns false when the context is done before
+func sleep(ctx context.Context, delay time.Duration) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 // Error type represents list of errors in retry
 type Error []error
 
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -162,3 +163,58 @@ func TestMaxDelay(t *testing.T) {
 	assert.True(t, dur > 170*time.Millisecond, "5 times with maximum delay retry is longer than 70ms")
 	assert.True(t, dur < 200*time.Millisecond, "5 times with maximum delay retry is shorter than 200ms")
 }
+
+func TestErrorDelay(t *testing.T) {
+	slowErr := errors.New("slow")
+	start := time.Now()
+	err := Do(
+		func() error { return slowErr },
+		Attempts(3),
+		Delay(10*time.Millisecond),
+		DelayType(FixedDelay),
+		ErrorDelay(func(err error) (time.Duration, time.Duration) {
+			if err == slowErr {
+				return 50 * time.Millisecond, 0
+			}
+			return 0, 0
+		}),
+	)
+	dur := time.Since(start)
+	assert.Error(t, err)
+	assert.True(t, dur > 100*time.Millisecond, "3 times with min error delay retry is longer than 100ms")
+	assert.True(t, dur < 150*time.Millisecond, "3 times with min error delay retry is shorter than 150ms")
+
+	start = time.Now()
+	err = Do(
+		func() error { return errors.New("test") },
+		Attempts(3),
+		Delay(100*time.Millisecond),
+		DelayType(FixedDelay),
+		MaxDelay(200*time.Millisecond),
+		ErrorDelay(func(err error) (time.Duration, time.Duration) {
+			return 0, 10 * time.Millisecond
+		}),
+	)
+	dur = time.Since(start)
+	assert.Error(t, err)
+	assert.True(t, dur < 50*time.Millisecond, "3 times with max error delay retry is shorter than 50ms")
+}
+
+func TestContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var retrySum uint
+	start := time.Now()
+	err := Do(
+		func() error { return errors.New("test") },
+		Attempts(3),
+		Delay(time.Second),
+		DelayType(FixedDelay),
+		Context(ctx),
+		OnRetry(func(n uint, err error) { retrySum += 1 }),
+	)
+	dur := time.Since(start)
+	assert.Error(t, err)
+	assert.Equal(t, uint(1), retrySum, "no retries after the context is done")
+	assert.True(t, dur < 200*time.Millisecond, "retry delay is stopped when the context is done")
+}
@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const RedactedValue = "******"
+
+var referenceRegex = regexp.MustCompile(`\$\{([a-zA-Z0-9_-]+):([^}]+)\}`)
+
+// Provider resolves a secret reference of the form ${<provider>:<ref>} to its value
+type Provider interface {
+	Get(ref string) (string, error)
+}
+
+type ProviderFunc func(ref string) (string, error)
+
+func (f ProviderFunc) Get(ref string) (string, error) {
+	return f(ref)
+}
+
+var providers = sync.Map{}
+
+func init() {
+	Register("env", ProviderFunc(getEnv))
+	Register("file", ProviderFunc(getFile))
+}
+
+// Register adds or replaces a secret provider for the given reference prefix
+func Register(name string, provider Provider) {
+	providers.Store(name, provider)
+}
+
+func getEnv(ref string) (string, error) {
+	val, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s not found", ref)
+	}
+	return val, nil
+}
+
+func getFile(ref string) (string, error) {
+	/* #nosec */
+	data, err := ioutil.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// DirectoryProvider reads secrets from files under a root directory, such as mounted Kubernetes secrets,
+// where a reference of <secret>/<key> is read from <root>/<secret>/<key>
+type DirectoryProvider struct {
+	root string
+}
+
+func NewDirectoryProvider(root string) *DirectoryProvider {
+	return &DirectoryProvider{
+		root: root,
+	}
+}
+
+func (d *DirectoryProvider) Get(ref string) (string, error) {
+	path := filepath.Join(d.root, filepath.FromSlash(ref))
+	rel, err := filepath.Rel(d.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid secret reference %s", ref)
+	}
+	return getFile(path)
+}
+
+// IsReference reports whether the value contains a secret reference
+func IsReference(value string) bool {
+	return referenceRegex.MatchString(value)
+}
+
+// ResolveValue replaces all secret references in the value with their resolved values
+func ResolveValue(value string) (string, error) {
+	var resolveErr error
+	resolved := referenceRegex.ReplaceAllStringFunc(value, func(ref string) string {
+		parts := referenceRegex.FindStringSubmatch(ref)
+		val, ok := providers.Load(parts[1])
+		if !ok {
+			resolveErr = fmt.Errorf("unknown secret provider %s", parts[1])
+			return ref
+		}
+		secret, err := val.(Provider).Get(parts[2])
+		if err != nil {
+			resolveErr = fmt.Errorf("error resolving secret reference %s, %w", ref, err)
+			return ref
+		}
+		return secret
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// Resolve returns a copy of the properties with all secret references resolved
+func Resolve(properties types.Metadata) (types.Metadata, error) {
+	if properties == nil {
+		return nil, nil
+	}
+	resolved := types.NewMetadata()
+	for key, value := range properties {
+		val, err := ResolveValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("property %s, %w", key, err)
+		}
+		resolved[key] = val
+	}
+	return resolved, nil
+}
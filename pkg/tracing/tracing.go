@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/config"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/kubemq-hub/kubemq-targets"
+
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+func init() {
+	otel.SetTextMapPropagator(propagator)
+}
+
+// Init sets the global tracer provider according to the tracing config and returns its shutdown function
+func Init(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case config.TracingExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case config.TracingExporterStdout:
+		exporter, err = stdout.NewExporter(stdout.WithPrettyPrint(), stdout.WithoutMetricExport())
+	case config.TracingExporterOTLP:
+		opts := []otlpgrpc.Option{otlpgrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlpgrpc.WithInsecure())
+		}
+		exporter, err = otlp.NewExporter(ctx, otlpgrpc.NewDriver(opts...))
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter %s", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating tracing exporter, %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(sdkresource.NewWithAttributes(semconv.ServiceNameKey.String(cfg.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// MetadataCarrier adapts request metadata to be a propagation carrier
+type MetadataCarrier types.Metadata
+
+func (m MetadataCarrier) Get(key string) string {
+	return m[key]
+}
+
+func (m MetadataCarrier) Set(key, value string) {
+	m[key] = value
+}
+
+func (m MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// IsPropagationKey returns true when key is a trace context propagation metadata key, such as traceparent
+func IsPropagationKey(key string) bool {
+	for _, field := range propagator.Fields() {
+		if field == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Extract returns a context with the remote span context found in the metadata
+func Extract(ctx context.Context, metadata types.Metadata) context.Context {
+	if metadata == nil {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, MetadataCarrier(metadata))
+}
+
+// Headers returns the current span context as W3C trace context headers
+func Headers(ctx context.Context) map[string]string {
+	headers := map[string]string{}
+	otel.GetTextMapPropagator().Inject(ctx, MetadataCarrier(headers))
+	return headers
+}
+
+// CopyFromTags copies trace context values from KubeMQ message tags to the request metadata when missing
+func CopyFromTags(tags map[string]string, request *types.Request) {
+	if request == nil {
+		return
+	}
+	for _, key := range otel.GetTextMapPropagator().Fields() {
+		value, ok := tags[key]
+		if !ok || value == "" {
+			continue
+		}
+		if request.Metadata == nil {
+			request.Metadata = types.NewMetadata()
+		}
+		if request.Metadata[key] == "" {
+			request.Metadata[key] = value
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"hash/fnv"
+	"sync"
+)
+
+var ErrClosed = errors.New("worker pool is closed")
+
+// Pool runs tasks on a fixed number of workers. Tasks submitted with the same key run on the same worker
+// in submit order, tasks without a key run on the first free worker.
+type Pool struct {
+	shared chan func()
+	keyed  []chan func()
+	quit   chan struct{}
+	once   sync.Once
+}
+
+func New(concurrency int) *Pool {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	p := &Pool{
+		shared: make(chan func()),
+		keyed:  make([]chan func(), concurrency),
+		quit:   make(chan struct{}),
+	}
+	for i := range p.keyed {
+		p.keyed[i] = make(chan func())
+		go p.work(p.keyed[i])
+	}
+	return p
+}
+
+func (p *Pool) work(keyed chan func()) {
+	for {
+		select {
+		case task := <-keyed:
+			task()
+		case task := <-p.shared:
+			task()
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// Submit blocks until a worker accepts the task, the context is done or the pool is closed
+func (p *Pool) Submit(ctx context.Context, key string, task func()) error {
+	queue := p.shared
+	if key != "" {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(key))
+		queue = p.keyed[h.Sum32()%uint32(len(p.keyed))]
+	}
+	select {
+	case queue <- task:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.quit:
+		return ErrClosed
+	}
+}
+
+// Close stops the workers once their running tasks are completed, tasks which were not accepted are rejected
+func (p *Pool) Close() {
+	p.once.Do(func() {
+		close(p.quit)
+	})
+}
+
+// OrderingKey returns the value of key from the message tags, or from the metadata of the request in the message body
+func OrderingKey(key string, tags map[string]string, body []byte) string {
+	if key == "" {
+		return ""
+	}
+	if value, ok := tags[key]; ok {
+		return value
+	}
+	req, err := types.ParseRequest(body)
+	if err != nil {
+		return ""
+	}
+	return req.Metadata.Get(key)
+}
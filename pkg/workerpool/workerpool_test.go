@@ -0,0 +1,142 @@
+package workerpool
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_Submit(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		tasks       int
+		keys        []string
+		wantMax     int32
+	}{
+		{
+			name:        "sequential",
+			concurrency: 1,
+			tasks:       10,
+			wantMax:     1,
+		},
+		{
+			name:        "concurrent",
+			concurrency: 5,
+			tasks:       10,
+			wantMax:     5,
+		},
+		{
+			name:        "same key runs sequentially",
+			concurrency: 5,
+			tasks:       10,
+			keys:        []string{"key"},
+			wantMax:     1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			p := New(tt.concurrency)
+			defer p.Close()
+			running := int32(0)
+			maxRunning := int32(0)
+			mu := sync.Mutex{}
+			var order []int
+			wg := sync.WaitGroup{}
+			for i := 0; i < tt.tasks; i++ {
+				key := ""
+				if len(tt.keys) > 0 {
+					key = tt.keys[i%len(tt.keys)]
+				}
+				i := i
+				wg.Add(1)
+				err := p.Submit(ctx, key, func() {
+					defer wg.Done()
+					current := atomic.AddInt32(&running, 1)
+					for {
+						max := atomic.LoadInt32(&maxRunning)
+						if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+							break
+						}
+					}
+					time.Sleep(20 * time.Millisecond)
+					mu.Lock()
+					order = append(order, i)
+					mu.Unlock()
+					atomic.AddInt32(&running, -1)
+				})
+				require.NoError(t, err)
+			}
+			wg.Wait()
+			require.Equal(t, tt.wantMax, atomic.LoadInt32(&maxRunning))
+			if tt.wantMax == 1 {
+				for i := range order {
+					require.Equal(t, i, order[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPool_Close(t *testing.T) {
+	p := New(1)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	require.NoError(t, p.Submit(context.Background(), "", func() {
+		close(started)
+		<-release
+	}))
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, p.Submit(ctx, "", func() {}))
+	p.Close()
+	require.Equal(t, ErrClosed, p.Submit(context.Background(), "", func() {}))
+	close(release)
+}
+
+func TestOrderingKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		tags map[string]string
+		body []byte
+		want string
+	}{
+		{
+			name: "no ordering key",
+			key:  "",
+			tags: map[string]string{"id": "1"},
+			want: "",
+		},
+		{
+			name: "from tags",
+			key:  "id",
+			tags: map[string]string{"id": "1"},
+			body: []byte(`{"metadata":{"id":"2"}}`),
+			want: "1",
+		},
+		{
+			name: "from request metadata",
+			key:  "id",
+			body: []byte(`{"metadata":{"id":"2"}}`),
+			want: "2",
+		},
+		{
+			name: "invalid request",
+			key:  "id",
+			body: []byte(`bad-request`),
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, OrderingKey(tt.key, tt.tags, tt.body))
+		})
+	}
+}
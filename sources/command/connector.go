@@ -2,10 +2,11 @@ package command
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("kubemq.command").
 		SetDescription("Kubemq Command Source").
 		SetName("KubeMQ Command").
@@ -46,6 +47,24 @@ func Connector() *common.Connector {
 				SetMust(false).
 				SetDefault("1"),
 		).
+		AddProperty(
+			common.NewProperty().
+				SetKind("int").
+				SetName("concurrency").
+				SetTitle("Concurrency").
+				SetDescription("Set how many messages to process concurrently on each source, 0 - unlimited").
+				SetMust(false).
+				SetDefault("0"),
+		).
+		AddProperty(
+			common.NewProperty().
+				SetKind("string").
+				SetName("ordering_key").
+				SetTitle("Ordering Key").
+				SetDescription("Set a message tag or request metadata key, messages with the same key value are processed in order").
+				SetMust(false).
+				SetDefault(""),
+		).
 		AddProperty(
 			common.NewProperty().
 				SetKind("string").
@@ -91,4 +110,5 @@ func Connector() *common.Connector {
 				SetMust(false).
 				SetDefault("0"),
 		)
+	return secrets.MarkSecretProperties(connector, "auth_token")
 }
@@ -10,6 +10,7 @@ import (
 const (
 	defaultAutoReconnect = true
 	defaultSources       = 1
+	defaultConcurrency   = 0
 )
 
 type options struct {
@@ -23,6 +24,8 @@ type options struct {
 	reconnectIntervalSeconds time.Duration
 	maxReconnects            int
 	sources                  int
+	concurrency              int
+	orderingKey              string
 }
 
 func parseOptions(cfg config.Spec) (options, error) {
@@ -44,6 +47,11 @@ func parseOptions(cfg config.Spec) (options, error) {
 	if err != nil {
 		return options{}, fmt.Errorf("error parsing sources value, %w", err)
 	}
+	o.concurrency, err = cfg.Properties.ParseIntWithRange("concurrency", defaultConcurrency, 0, 1024)
+	if err != nil {
+		return options{}, fmt.Errorf("error parsing concurrency value, %w", err)
+	}
+	o.orderingKey = cfg.Properties.ParseString("ordering_key", "")
 
 	o.group = cfg.Properties.ParseString("group", "")
 	o.autoReconnect = cfg.Properties.ParseBool("auto_reconnect", defaultAutoReconnect)
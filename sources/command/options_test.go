@@ -127,6 +127,19 @@ func TestOptions_parseOptions(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid options - bad concurrency",
+			cfg: config.Spec{
+				Name: "kubemq-rpc",
+				Kind: "",
+				Properties: map[string]string{
+					"address":     "localhost:50000",
+					"channel":     "some-channel",
+					"concurrency": "-1",
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
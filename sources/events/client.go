@@ -7,8 +7,11 @@ import (
 	"github.com/kubemq-hub/builder/connector/common"
 	"github.com/kubemq-hub/kubemq-targets/config"
 	"github.com/kubemq-hub/kubemq-targets/middleware"
+	"github.com/kubemq-hub/kubemq-targets/pkg/drain"
 	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/pkg/tracing"
 	"github.com/kubemq-hub/kubemq-targets/pkg/uuid"
+	"github.com/kubemq-hub/kubemq-targets/pkg/workerpool"
 	"github.com/kubemq-hub/kubemq-targets/types"
 	"github.com/kubemq-io/kubemq-go"
 )
@@ -18,10 +21,12 @@ var (
 )
 
 type Client struct {
-	opts    options
-	clients []*kubemq.Client
-	log     *logger.Logger
-	target  middleware.Middleware
+	opts     options
+	clients  []*kubemq.Client
+	log      *logger.Logger
+	target   middleware.Middleware
+	inflight drain.Tracker
+	pool     *workerpool.Pool
 }
 
 func New() *Client {
@@ -70,6 +75,9 @@ func (c *Client) Start(ctx context.Context, target middleware.Middleware) error
 	} else {
 		c.target = target
 	}
+	if c.opts.concurrency > 0 {
+		c.pool = workerpool.New(c.opts.concurrency)
+	}
 	if c.opts.sources > 1 && c.opts.group == "" {
 		c.opts.group = uuid.New().String()
 	}
@@ -92,18 +100,18 @@ func (c *Client) runClient(ctx context.Context, client *kubemq.Client) error {
 		for {
 			select {
 			case event := <-eventsCh:
-				go func(event *kubemq.Event) {
+				if !c.inflight.Acquire() {
+					c.sendResponse(ctx, client, types.NewResponse().SetError(drain.ErrDraining))
+					continue
+				}
+				c.dispatch(ctx, event.Tags, event.Body, func() {
+					defer c.inflight.Release()
 					resp, err := c.processEvent(ctx, event)
 					if err != nil {
 						resp = types.NewResponse().SetError(err)
 					}
-					if c.opts.responseChannel != "" {
-						errSend := client.SetEvent(resp.ToEvent()).SetChannel(c.opts.responseChannel).Send(ctx)
-						if errSend != nil {
-							c.log.Errorf("error sending event response %s", errSend.Error())
-						}
-					}
-				}(event)
+					c.sendResponse(ctx, client, resp)
+				})
 
 			case err := <-errCh:
 				c.log.Errorf("error received from kuebmq server, %s", err.Error())
@@ -121,13 +129,47 @@ func (c *Client) processEvent(ctx context.Context, event *kubemq.Event) (*types.
 	if err != nil {
 		return nil, fmt.Errorf("invalid request format, %w", err)
 	}
+	tracing.CopyFromTags(event.Tags, req)
 	resp, err := c.target.Do(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
+
+func (c *Client) sendResponse(ctx context.Context, client *kubemq.Client, resp *types.Response) {
+	if c.opts.responseChannel == "" {
+		return
+	}
+	errSend := client.SetEvent(resp.ToEvent()).SetChannel(c.opts.responseChannel).Send(ctx)
+	if errSend != nil {
+		c.log.Errorf("error sending event response %s", errSend.Error())
+	}
+}
+
+// dispatch runs the handler of an in-flight message on the worker pool when concurrency is set, otherwise on a new
+// goroutine. A message which cannot be dispatched is released from the in-flight messages
+func (c *Client) dispatch(ctx context.Context, tags map[string]string, body []byte, handler func()) {
+	if c.pool == nil {
+		go handler()
+		return
+	}
+	key := workerpool.OrderingKey(c.opts.orderingKey, tags, body)
+	if err := c.pool.Submit(ctx, key, handler); err != nil {
+		c.inflight.Release()
+		c.log.Errorf("error dispatching message to worker pool, %s", err.Error())
+	}
+}
+
+// Drain stops processing new messages and waits for in-flight messages to complete
+func (c *Client) Drain(ctx context.Context) error {
+	return c.inflight.Drain(ctx)
+}
+
 func (c *Client) Stop() error {
+	if c.pool != nil {
+		c.pool.Close()
+	}
 	for _, client := range c.clients {
 		_ = client.Close()
 	}
@@ -7,7 +7,10 @@ import (
 	"github.com/kubemq-hub/builder/connector/common"
 	"github.com/kubemq-hub/kubemq-targets/config"
 	"github.com/kubemq-hub/kubemq-targets/middleware"
+	"github.com/kubemq-hub/kubemq-targets/pkg/drain"
 	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/pkg/tracing"
+	"github.com/kubemq-hub/kubemq-targets/pkg/workerpool"
 	"github.com/kubemq-hub/kubemq-targets/types"
 	"github.com/kubemq-io/kubemq-go/queues_stream"
 	"time"
@@ -18,10 +21,11 @@ var (
 )
 
 type Client struct {
-	opts      options
-	log       *logger.Logger
-	target    middleware.Middleware
-	isStopped bool
+	opts     options
+	log      *logger.Logger
+	target   middleware.Middleware
+	inflight drain.Tracker
+	pool     *workerpool.Pool
 }
 
 func (c *Client) getQueuesClient(ctx context.Context, id int) (*queues_stream.QueuesStreamClient, error) {
@@ -68,6 +72,9 @@ func (c *Client) Start(ctx context.Context, target middleware.Middleware) error
 	} else {
 		c.target = target
 	}
+	if c.opts.concurrency > 1 {
+		c.pool = workerpool.New(c.opts.concurrency)
+	}
 	for i := 0; i < c.opts.sources; i++ {
 		client, err := c.getQueuesClient(ctx, i+1)
 		if err != nil {
@@ -83,7 +90,7 @@ func (c *Client) run(ctx context.Context, client *queues_stream.QueuesStreamClie
 		_ = client.Close()
 	}()
 	for {
-		if c.isStopped {
+		if c.inflight.IsDraining() {
 			return
 		}
 		err := c.processQueueMessage(ctx, client)
@@ -113,45 +120,115 @@ func (c *Client) processQueueMessage(ctx context.Context, client *queues_stream.
 	if !pollResp.HasMessages() {
 		return nil
 	}
-
-	for _, message := range pollResp.Messages {
-		req, err := types.ParseRequest(message.Body)
-		if err != nil {
-			_ = message.Ack()
-			return fmt.Errorf("invalid request format, %w", err)
-		}
-		resp, err := c.target.Do(ctx, req)
-		if err != nil {
-			if message.Policy.MaxReceiveCount < 1024 && message.Policy.MaxReceiveCount != message.Attributes.ReceiveCount {
-				return message.NAck()
+	for i, message := range pollResp.Messages {
+		if !c.inflight.Acquire() {
+			for _, pending := range pollResp.Messages[i:] {
+				_ = pending.NAck()
 			}
-			if c.opts.responseChannel != "" {
-				errResp := types.NewResponse().SetError(err)
-				_, errSend := client.Send(ctx, errResp.ToQueueStreamMessage().SetChannel(c.opts.responseChannel))
-				if errSend != nil {
-					c.log.Errorf("error sending response to a queue, %s", errSend.Error())
-				}
+			return nil
+		}
+		if c.pool == nil {
+			err := c.processMessage(ctx, client, message)
+			c.inflight.Release()
+			if err != nil {
+				return err
 			}
+			continue
 		}
-
-		err = message.Ack()
+		key := ""
+		if c.opts.orderingKey != "" {
+			key = workerpool.OrderingKey(c.opts.orderingKey, message.Tags, message.Body)
+		}
+		message := message
+		err := c.pool.Submit(ctx, key, func() {
+			defer c.inflight.Release()
+			if err := c.processMessage(ctx, client, message); err != nil {
+				c.log.Error(err.Error())
+			}
+		})
 		if err != nil {
+			c.inflight.Release()
+			for _, pending := range pollResp.Messages[i:] {
+				_ = pending.NAck()
+			}
 			return err
 		}
+	}
+	return nil
+}
 
-		if resp != nil {
-			if c.opts.responseChannel != "" {
-				_, errSend := client.Send(ctx, resp.ToQueueStreamMessage().SetChannel(c.opts.responseChannel))
-				if errSend != nil {
-					c.log.Errorf("error sending response to a queue, %s", errSend.Error())
-				}
-			}
+func (c *Client) processMessage(ctx context.Context, client *queues_stream.QueuesStreamClient, message *queues_stream.QueueMessage) error {
+	req, err := types.ParseRequest(message.Body)
+	if err != nil {
+		_ = message.Ack()
+		return fmt.Errorf("invalid request format, %w", err)
+	}
+	tracing.CopyFromTags(message.Tags, req)
+	if c.opts.messageAttributes {
+		req.SetQueueMessageAttributes(message)
+	}
+	resp, err := c.target.Do(ctx, req)
+	if err != nil {
+		if errors.Is(err, middleware.ErrCircuitOpen) {
+			c.waitCircuitOpen(ctx, types.RetryAfterOf(err))
+			return message.NAck()
+		}
+		if message.Policy.MaxReceiveCount < 1024 && message.Policy.MaxReceiveCount != message.Attributes.ReceiveCount {
+			return message.NAck()
+		}
+		if c.opts.responseChannel != "" {
+			c.sendResponse(ctx, client, types.NewResponse().SetError(err))
+		}
+	}
+
+	err = message.Ack()
+	if err != nil {
+		return err
+	}
+
+	if resp != nil {
+		if c.opts.responseChannel != "" {
+			c.sendResponse(ctx, client, resp)
 		}
 	}
 	return nil
 }
 
+// waitCircuitOpen waits out the open interval of the binding circuit breaker before a rejected message is redelivered,
+// so the message is not received again, and its receive count is not exhausted, while the circuit is open
+func (c *Client) waitCircuitOpen(ctx context.Context, retryAfter time.Duration) {
+	if retryAfter <= 0 {
+		return
+	}
+	timer := time.NewTimer(retryAfter)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+func (c *Client) sendResponse(ctx context.Context, client *queues_stream.QueuesStreamClient, resp *types.Response) {
+	msg, err := resp.ToQueueStreamMessage()
+	if err != nil {
+		c.log.Errorf("invalid response queue message policy, %s", err.Error())
+		msg, _ = types.NewResponse().SetError(fmt.Errorf("invalid response queue message policy, %w", err)).ToQueueStreamMessage()
+	}
+	_, errSend := client.Send(ctx, msg.SetChannel(c.opts.responseChannel))
+	if errSend != nil {
+		c.log.Errorf("error sending response to a queue, %s", errSend.Error())
+	}
+}
+
+// Drain stops polling new messages and waits for polled messages to be processed and acked
+func (c *Client) Drain(ctx context.Context) error {
+	return c.inflight.Drain(ctx)
+}
+
 func (c *Client) Stop() error {
-	c.isStopped = true
+	c.inflight.Stop()
+	if c.pool != nil {
+		c.pool.Close()
+	}
 	return nil
 }
@@ -2,10 +2,11 @@ package queue
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("kubemq.queue").
 		SetDescription("Kubemq Queue Source").
 		SetName("KubeMQ Queue").
@@ -47,6 +48,33 @@ func Connector() *common.Connector {
 				SetMust(false).
 				SetDefault("1"),
 		).
+		AddProperty(
+			common.NewProperty().
+				SetKind("int").
+				SetName("concurrency").
+				SetTitle("Concurrency").
+				SetDescription("Set how many messages to process concurrently on each source").
+				SetMust(false).
+				SetDefault("1"),
+		).
+		AddProperty(
+			common.NewProperty().
+				SetKind("string").
+				SetName("ordering_key").
+				SetTitle("Ordering Key").
+				SetDescription("Set a message tag or request metadata key, messages with the same key value are processed in order").
+				SetMust(false).
+				SetDefault(""),
+		).
+		AddProperty(
+			common.NewProperty().
+				SetKind("bool").
+				SetName("message_attributes").
+				SetTitle("Message Attributes").
+				SetDescription("Set queue message id, tags, sequence, receive count and timestamp as request metadata").
+				SetMust(false).
+				SetDefault("false"),
+		).
 		AddProperty(
 			common.NewProperty().
 				SetKind("string").
@@ -83,4 +111,5 @@ func Connector() *common.Connector {
 				SetMust(false).
 				SetDefault("5"),
 		)
+	return secrets.MarkSecretProperties(connector, "auth_token")
 }
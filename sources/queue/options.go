@@ -10,18 +10,22 @@ const (
 	defaultAddress     = "localhost:50000"
 	defaultWaitTimeout = 5
 	defaultSources     = 1
+	defaultConcurrency = 1
 )
 
 type options struct {
-	host            string
-	port            int
-	clientId        string
-	authToken       string
-	channel         string
-	responseChannel string
-	sources         int
-	batchSize       int
-	waitTimeout     int
+	host              string
+	port              int
+	clientId          string
+	authToken         string
+	channel           string
+	responseChannel   string
+	sources           int
+	concurrency       int
+	orderingKey       string
+	messageAttributes bool
+	batchSize         int
+	waitTimeout       int
 }
 
 func parseOptions(cfg config.Spec) (options, error) {
@@ -45,6 +49,12 @@ func parseOptions(cfg config.Spec) (options, error) {
 	if err != nil {
 		return options{}, fmt.Errorf("error parsing sources value, %w", err)
 	}
+	o.concurrency, err = cfg.Properties.ParseIntWithRange("concurrency", defaultConcurrency, 1, 1024)
+	if err != nil {
+		return options{}, fmt.Errorf("error parsing concurrency value, %w", err)
+	}
+	o.orderingKey = cfg.Properties.ParseString("ordering_key", "")
+	o.messageAttributes = cfg.Properties.ParseBool("message_attributes", false)
 
 	o.batchSize, err = cfg.Properties.ParseIntWithRange("batch_size", 1, 1, 1024)
 	if err != nil {
@@ -27,6 +27,8 @@ func TestOptions_parseOptions(t *testing.T) {
 					"response_channel":           "some-response-channel",
 					"batch_size": "2",
 					"wait_timeout":               "60",
+					"concurrency":                "4",
+					"ordering_key":               "order_id",
 				},
 			},
 			want: options{
@@ -37,6 +39,8 @@ func TestOptions_parseOptions(t *testing.T) {
 				channel:           "some-channel",
 				responseChannel:   "some-response-channel",
 				sources:           1,
+				concurrency:       4,
+				orderingKey:       "order_id",
 				waitTimeout:       60,
 				batchSize: 2,
 			},
@@ -115,6 +119,20 @@ func TestOptions_parseOptions(t *testing.T) {
 			want:    options{},
 			wantErr: true,
 		},
+		{
+			name: "invalid options - bad concurrency",
+			cfg: config.Spec{
+				Name: "kubemq-rpc",
+				Kind: "",
+				Properties: map[string]string{
+					"address":     "localhost:50000",
+					"channel":     "channel",
+					"concurrency": "0",
+				},
+			},
+			want:    options{},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
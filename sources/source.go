@@ -7,21 +7,46 @@ import (
 	"github.com/kubemq-hub/kubemq-targets/config"
 	"github.com/kubemq-hub/kubemq-targets/middleware"
 	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"github.com/kubemq-hub/kubemq-targets/sources/command"
 	"github.com/kubemq-hub/kubemq-targets/sources/events"
 	events_store "github.com/kubemq-hub/kubemq-targets/sources/events-store"
 	"github.com/kubemq-hub/kubemq-targets/sources/query"
 	"github.com/kubemq-hub/kubemq-targets/sources/queue"
+	"strings"
 )
 
 type Source interface {
 	Init(ctx context.Context, cfg config.Spec, log *logger.Logger) error
 	Start(ctx context.Context, target middleware.Middleware) error
+	Drain(ctx context.Context) error
 	Stop() error
 	Connector() *common.Connector
 }
 
+// Init resolves secret references of the source properties and initializes the source connector
 func Init(ctx context.Context, cfg config.Spec, log *logger.Logger) (Source, error) {
+	properties, err := secrets.Resolve(cfg.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving secrets, %w", err)
+	}
+	cfg.Properties = properties
+	connector, err := initSource(ctx, cfg, log)
+	if err != nil {
+		return nil, secrets.RedactError(err, ConnectorKind(cfg.Kind), properties)
+	}
+	return connector, nil
+}
+
+// ConnectorKind returns the connector definition kind of a source kind, source.<type> kinds are aliases of kubemq.<type>
+func ConnectorKind(kind string) string {
+	if strings.HasPrefix(kind, "source.") {
+		return "kubemq." + strings.TrimPrefix(kind, "source.")
+	}
+	return kind
+}
+
+func initSource(ctx context.Context, cfg config.Spec, log *logger.Logger) (Source, error) {
 	switch cfg.Kind {
 	case "source.command", "kubemq.command":
 		source := command.New()
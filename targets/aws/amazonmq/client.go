@@ -53,7 +53,7 @@ func (c *Client) Do(ctx context.Context, req *types.Request) (*types.Response, e
 		var err error
 		meta, err = parseMetadata(req.Metadata)
 		if err != nil {
-			return nil, err
+			return nil, types.NewInvalidRequestError(err)
 		}
 	}
 	err := c.conn.Send(meta.destination, "text/plain", req.Data)
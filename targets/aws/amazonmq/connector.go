@@ -2,10 +2,11 @@ package amazonmq
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.amazonmq").
 		SetDescription("AWS AmazonMQ Target").
 		SetName("AmazonMQ").
@@ -53,4 +54,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(true),
 		)
+	return secrets.MarkSecretProperties(connector, "password")
 }
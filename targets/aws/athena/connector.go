@@ -2,10 +2,11 @@ package athena
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.athena").
 		SetDescription("AWS Athena Target").
 		SetName("Athena").
@@ -93,4 +94,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "aws_secret_key", "token")
 }
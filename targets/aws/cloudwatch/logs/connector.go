@@ -2,11 +2,12 @@ package logs
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.cloudwatch.logs").
 		SetDescription("AWS Cloudwatch Logs Target").
 		SetName("Cloudwatch Logs").
@@ -96,5 +97,5 @@ func Connector() *common.Connector {
 				SetMin(0).
 				SetMax(math.MaxInt32),
 		)
-
+	return secrets.MarkSecretProperties(connector, "aws_secret_key", "token")
 }
@@ -1,9 +1,12 @@
 package metrics
 
-import "github.com/kubemq-hub/builder/connector/common"
+import (
+	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
+)
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.cloudwatch.metrics").
 		SetDescription("AWS Cloudwatch Metrics Target").
 		SetName("Cloudwatch Metrics").
@@ -59,4 +62,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "aws_secret_key", "token")
 }
@@ -2,10 +2,11 @@ package dynamodb
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.dynamodb").
 		SetDescription("AWS Dynamodb Target").
 		SetName("DynamoDB").
@@ -61,4 +62,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "aws_secret_key", "token")
 }
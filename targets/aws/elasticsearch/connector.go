@@ -2,10 +2,11 @@ package elasticsearch
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.elasticsearch").
 		SetDescription("AWS Elastic Search Target").
 		SetName("Elasticsearch").
@@ -101,4 +102,5 @@ func Connector() *common.Connector {
 				SetMust(false).
 				SetDefault("es"),
 		)
+	return secrets.MarkSecretProperties(connector, "aws_secret_key", "token")
 }
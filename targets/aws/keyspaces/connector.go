@@ -2,11 +2,12 @@ package keyspaces
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.keyspaces").
 		SetDescription("AWS Keyspaces Target").
 		SetName("Keyspaces").
@@ -166,4 +167,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "password")
 }
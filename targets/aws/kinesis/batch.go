@@ -0,0 +1,70 @@
+package kinesis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/kubemq-hub/kubemq-targets/types"
+)
+
+const maxPutRecords = 500
+
+// DoBatch sends put_record requests of the same stream with put_records calls, other requests are executed one by one
+func (c *Client) DoBatch(ctx context.Context, requests []*types.Request) ([]*types.Response, []error, error) {
+	responses := make([]*types.Response, len(requests))
+	errs := make([]error, len(requests))
+	streams := map[string][]int{}
+	for i, req := range requests {
+		meta, err := parseMetadata(req.Metadata)
+		if err != nil || meta.method != "put_record" {
+			responses[i], errs[i] = c.Do(ctx, req)
+			continue
+		}
+		streams[meta.streamName] = append(streams[meta.streamName], i)
+	}
+	for stream, indexes := range streams {
+		for start := 0; start < len(indexes); start += maxPutRecords {
+			end := start + maxPutRecords
+			if end > len(indexes) {
+				end = len(indexes)
+			}
+			c.putRecordsBatch(ctx, stream, requests, indexes[start:end], responses, errs)
+		}
+	}
+	return responses, errs, nil
+}
+
+func (c *Client) putRecordsBatch(ctx context.Context, stream string, requests []*types.Request, indexes []int, responses []*types.Response, errs []error) {
+	var records []*kinesis.PutRecordsRequestEntry
+	for _, i := range indexes {
+		records = append(records, &kinesis.PutRecordsRequestEntry{
+			Data:         requests[i].Data,
+			PartitionKey: aws.String(requests[i].Metadata.Get("partition_key")),
+		})
+	}
+	out, err := c.client.PutRecordsWithContext(ctx, &kinesis.PutRecordsInput{
+		Records:    records,
+		StreamName: aws.String(stream),
+	})
+	for j, i := range indexes {
+		switch {
+		case err != nil:
+			errs[i] = err
+		case j >= len(out.Records):
+			errs[i] = fmt.Errorf("no put record result")
+		case out.Records[j].ErrorCode != nil:
+			errs[i] = fmt.Errorf("%s, %s", aws.StringValue(out.Records[j].ErrorCode), aws.StringValue(out.Records[j].ErrorMessage))
+		default:
+			b, err := json.Marshal(out.Records[j])
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			responses[i] = types.NewResponse().
+				SetMetadataKeyValue("result", "ok").
+				SetData(b)
+		}
+	}
+}
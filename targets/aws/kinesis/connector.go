@@ -2,11 +2,12 @@ package kinesis
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.kinesis").
 		SetDescription("AWS Kinesis Target").
 		SetName("Kinesis").
@@ -50,7 +51,7 @@ func Connector() *common.Connector {
 				SetName("method").
 				SetKind("string").
 				SetDescription("Set Kinesis execution method").
-				SetOptions([]string{"list_streams", "list_stream_consumers", "create_stream", "delete_stream", "put_record", "put_records", "get_records", "get_shard_iterator", "list_shards"}).
+				SetOptions([]string{"list_streams", "list_stream_consumers", "create_stream", "delete_stream", "put_record", "put_records", "get_records", "get_shard_iterator", "list_shards", "create_stream_consumer"}).
 				SetDefault("put_record").
 				SetMust(true),
 		).
@@ -130,4 +131,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "aws_secret_key", "token")
 }
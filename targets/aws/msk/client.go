@@ -64,7 +64,7 @@ func (c *Client) Do(ctx context.Context, request *types.Request) (*types.Respons
 
 	m, err := parseMetadata(request.Metadata, c.opts)
 	if err != nil {
-		return nil, err
+		return nil, types.NewInvalidRequestError(err)
 	}
 
 	partition, offset, err := c.producer.SendMessage(&kafka.ProducerMessage{
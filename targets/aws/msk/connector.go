@@ -2,10 +2,11 @@ package msk
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.msk").
 		SetDescription("AWS MSK Target").
 		SetName("MSK").
@@ -63,5 +64,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
-
+	return secrets.MarkSecretProperties(connector, "sasl_password")
 }
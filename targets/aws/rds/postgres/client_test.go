@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/kubemq-hub/kubemq-targets/config"
 	"github.com/kubemq-hub/kubemq-targets/types"
 	"github.com/stretchr/testify/require"
@@ -11,6 +12,8 @@ import (
 	"time"
 )
 
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
 type testStructure struct {
 	awsKey       string
 	awsSecretKey string
@@ -3,15 +3,12 @@ package postgres
 import (
 	"fmt"
 	"github.com/kubemq-hub/kubemq-targets/config"
-	"math"
+	"github.com/kubemq-hub/kubemq-targets/targets/stores/sqlcore"
 )
 
 const (
-	defaultMaxIdleConnections           = 10
-	defaultMaxOpenConnections           = 100
-	defaultConnectionMaxLifetimeSeconds = 3600
-	defaultToken                        = ""
-	defaultDBPort                       = 5432
+	defaultToken  = ""
+	defaultDBPort = 5432
 )
 
 type options struct {
@@ -25,12 +22,8 @@ type options struct {
 	dbUser   string
 	endPoint string
 
-	// maxIdleConnections sets the maximum number of connections in the idle connection pool
-	maxIdleConnections int
-	//maxOpenConnections sets the maximum number of open connections to the database.
-	maxOpenConnections int
-	// connectionMaxLifetimeSeconds sets the maximum amount of time a connection may be reused.
-	connectionMaxLifetimeSeconds int
+	pool       sqlcore.PoolOptions
+	migrations sqlcore.MigrationOptions
 }
 
 func parseOptions(cfg config.Spec) (options, error) {
@@ -70,17 +63,13 @@ func parseOptions(cfg config.Spec) (options, error) {
 	if err != nil {
 		return options{}, fmt.Errorf("error parsing end_point , %w", err)
 	}
-	o.maxIdleConnections, err = cfg.Properties.ParseIntWithRange("max_idle_connections", defaultMaxIdleConnections, 1, math.MaxInt32)
+	o.pool, err = sqlcore.ParsePoolOptions(cfg.Properties)
 	if err != nil {
-		return options{}, fmt.Errorf("error parsing max idle connections value, %w", err)
+		return options{}, err
 	}
-	o.maxOpenConnections, err = cfg.Properties.ParseIntWithRange("max_open_connections", defaultMaxOpenConnections, 1, math.MaxInt32)
+	o.migrations, err = sqlcore.ParseMigrationOptions(cfg.Properties)
 	if err != nil {
-		return options{}, fmt.Errorf("error parsing max open connections value, %w", err)
-	}
-	o.connectionMaxLifetimeSeconds, err = cfg.Properties.ParseIntWithRange("connection_max_lifetime_seconds", defaultConnectionMaxLifetimeSeconds, 1, math.MaxInt32)
-	if err != nil {
-		return options{}, fmt.Errorf("error parsing connection max lifetime seconds value, %w", err)
+		return options{}, err
 	}
 	return o, nil
 }
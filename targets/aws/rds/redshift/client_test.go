@@ -2,6 +2,7 @@ package redshift
 
 import (
 	"context"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/kubemq-hub/kubemq-targets/config"
 	"github.com/kubemq-hub/kubemq-targets/types"
 	"github.com/stretchr/testify/require"
@@ -10,6 +11,8 @@ import (
 	"time"
 )
 
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
 type post struct {
 	Id      string `json:"id"`
 	Title   string `json:"title,omitempty"`
@@ -2,11 +2,12 @@ package redshift
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.rds.redshift").
 		SetDescription("AWS RDS Redshift Target").
 		SetName("Redshift").
@@ -53,12 +54,37 @@ func Connector() *common.Connector {
 				SetMin(1).
 				SetMax(math.MaxInt32),
 		).
+		AddProperty(
+			common.NewProperty().
+				SetKind("string").
+				SetName("migrations_dir").
+				SetTitle("Migrations Directory").
+				SetDescription("Set Redshift migrations directory of <version>_<name>.sql scripts").
+				SetMust(false).
+				SetDefault(""),
+		).
+		AddProperty(
+			common.NewProperty().
+				SetKind("string").
+				SetName("migrations_table").
+				SetDescription("Set Redshift migrations versions table").
+				SetMust(false).
+				SetDefault("schema_migrations"),
+		).
+		AddProperty(
+			common.NewProperty().
+				SetKind("bool").
+				SetName("migrate_on_init").
+				SetDescription("Set Redshift apply pending migrations on init").
+				SetMust(false).
+				SetDefault("false"),
+		).
 		AddMetadata(
 			common.NewMetadata().
 				SetName("method").
 				SetKind("string").
 				SetDescription("Set Redshift execution method").
-				SetOptions([]string{"query", "exec", "transaction"}).
+				SetOptions([]string{"query", "exec", "transaction", "insert", "bulk_insert", "migrate"}).
 				SetDefault("query").
 				SetMust(true),
 		).
@@ -70,5 +96,65 @@ func Connector() *common.Connector {
 				SetOptions([]string{"read_uncommitted", "read_committed", "repeatable_read", "serializable", ""}).
 				SetDefault("read_committed").
 				SetMust(false),
+		).
+		AddMetadata(
+			common.NewMetadata().
+				SetName("params").
+				SetKind("string").
+				SetDescription("Set Redshift statement params, json array or object").
+				SetDefault("").
+				SetMust(false),
+		).
+		AddMetadata(
+			common.NewMetadata().
+				SetName("format").
+				SetKind("string").
+				SetDescription("Set Redshift query results format").
+				SetOptions([]string{"json", "json_lines", "csv"}).
+				SetDefault("json").
+				SetMust(false),
+		).
+		AddMetadata(
+			common.NewMetadata().
+				SetName("include_columns").
+				SetKind("bool").
+				SetDescription("Set Redshift query results columns metadata").
+				SetDefault("false").
+				SetMust(false),
+		).
+		AddMetadata(
+			common.NewMetadata().
+				SetName("include_nulls").
+				SetKind("bool").
+				SetDescription("Set Redshift json query results null columns").
+				SetDefault("false").
+				SetMust(false),
+		).
+		AddMetadata(
+			common.NewMetadata().
+				SetName("limit").
+				SetKind("int").
+				SetDescription("Set Redshift query results max rows, 0 for all rows").
+				SetDefault("0").
+				SetMin(0).
+				SetMax(math.MaxInt32).
+				SetMust(false),
+		).
+		AddMetadata(
+			common.NewMetadata().
+				SetName("cursor").
+				SetKind("string").
+				SetDescription("Set Redshift query results cursor of the next page").
+				SetDefault("").
+				SetMust(false),
+		).
+		AddMetadata(
+			common.NewMetadata().
+				SetName("table").
+				SetKind("string").
+				SetDescription("Set Redshift insert table").
+				SetDefault("").
+				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "connection")
 }
@@ -2,10 +2,11 @@ package s3
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.s3").
 		SetDescription("AWS S3 Target").
 		SetName("S3").
@@ -85,5 +86,5 @@ func Connector() *common.Connector {
 				SetMust(false).
 				SetDefault(""),
 		)
-
+	return secrets.MarkSecretProperties(connector, "aws_secret_key", "token")
 }
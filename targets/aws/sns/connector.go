@@ -2,10 +2,11 @@ package sns
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("aws.sns").
 		SetDescription("AWS SNS Target").
 		SetName("SNS").
@@ -108,4 +109,5 @@ func Connector() *common.Connector {
 				SetMust(false).
 				SetDefault(""),
 		)
+	return secrets.MarkSecretProperties(connector, "aws_secret_key", "token")
 }
@@ -61,7 +61,7 @@ func (c *Client) Do(ctx context.Context, request *types.Request) (*types.Respons
 		var err error
 		eventMetadata, err = parseMetadata(request.Metadata, c.opts)
 		if err != nil {
-			return nil, err
+			return nil, types.NewInvalidRequestError(err)
 		}
 	}
 	m := &sqs.SendMessageInput{}
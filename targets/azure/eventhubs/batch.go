@@ -0,0 +1,46 @@
+package eventhubs
+
+import (
+	"context"
+	"github.com/Azure/azure-event-hubs-go/v3"
+	"github.com/kubemq-hub/kubemq-targets/types"
+)
+
+// DoBatch sends all send requests with a single send batch call, other requests are executed one by one
+func (c *Client) DoBatch(ctx context.Context, requests []*types.Request) ([]*types.Response, []error, error) {
+	responses := make([]*types.Response, len(requests))
+	errs := make([]error, len(requests))
+	var events []*eventhub.Event
+	var indexes []int
+	for i, req := range requests {
+		meta, err := parseMetadata(req.Metadata)
+		if err != nil || meta.method != "send" {
+			responses[i], errs[i] = c.Do(ctx, req)
+			continue
+		}
+		event := &eventhub.Event{
+			Data: req.Data,
+		}
+		if meta.partitionKey != "" {
+			event.PartitionKey = &meta.partitionKey
+		}
+		if meta.properties != nil {
+			event.Properties = meta.properties
+		}
+		events = append(events, event)
+		indexes = append(indexes, i)
+	}
+	if len(events) == 0 {
+		return responses, errs, nil
+	}
+	err := c.client.SendBatch(ctx, eventhub.NewEventBatchIterator(events...))
+	for _, i := range indexes {
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		responses[i] = types.NewResponse().
+			SetMetadataKeyValue("result", "ok")
+	}
+	return responses, errs, nil
+}
@@ -2,10 +2,11 @@ package eventhubs
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("azure.eventhubs").
 		SetDescription("Azure EventHubs Target").
 		SetName("EventBus").
@@ -72,4 +73,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "shared_access_key")
 }
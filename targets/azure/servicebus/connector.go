@@ -2,11 +2,12 @@ package servicebus
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("azure.servicebus").
 		SetDescription("Azure Service Bus Target").
 		SetName("ServiceBus").
@@ -93,4 +94,5 @@ func Connector() *common.Connector {
 				SetMin(0).
 				SetMax(math.MaxInt32),
 		)
+	return secrets.MarkSecretProperties(connector, "shared_access_key")
 }
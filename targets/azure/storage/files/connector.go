@@ -2,11 +2,12 @@ package files
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("azure.storage.files").
 		SetDescription("Azure Files Storage Target").
 		SetName("Files").
@@ -163,4 +164,5 @@ func Connector() *common.Connector {
 				SetMin(0).
 				SetMax(math.MaxInt32),
 		)
+	return secrets.MarkSecretProperties(connector, "storage_access_key")
 }
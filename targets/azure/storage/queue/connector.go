@@ -2,11 +2,12 @@ package queue
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("azure.storage.queue").
 		SetDescription("Azure Queue Storage Target").
 		SetName("Queue").
@@ -142,4 +143,5 @@ func Connector() *common.Connector {
 				SetMin(1).
 				SetMax(math.MaxInt32),
 		)
+	return secrets.MarkSecretProperties(connector, "storage_access_key")
 }
@@ -0,0 +1,14 @@
+package targets
+
+import (
+	"context"
+	"github.com/kubemq-hub/kubemq-targets/types"
+)
+
+// BatchTarget is implemented by targets which can process a batch of requests with a single call.
+// DoBatch returns a response and an error per request in the same order, as returned by Do for requests executed one
+// by one, and an error is returned only when the whole batch failed.
+type BatchTarget interface {
+	Target
+	DoBatch(ctx context.Context, requests []*types.Request) ([]*types.Response, []error, error)
+}
@@ -2,11 +2,12 @@ package hazelcast
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("cache.hazelcast").
 		SetDescription("hazelcast source properties").
 		SetName("Hazelcast").
@@ -142,4 +143,5 @@ func Connector() *common.Connector {
 				SetDefault("get").
 				SetMust(true),
 		)
+	return secrets.MarkSecretProperties(connector, "password", "cert_key")
 }
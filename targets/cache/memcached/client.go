@@ -51,7 +51,7 @@ func (c *Client) Init(ctx context.Context, cfg config.Spec, log *logger.Logger)
 func (c *Client) Do(ctx context.Context, req *types.Request) (*types.Response, error) {
 	meta, err := parseMetadata(req.Metadata)
 	if err != nil {
-		return nil, err
+		return nil, types.NewInvalidRequestError(err)
 	}
 	switch meta.method {
 	case "get":
@@ -80,7 +80,7 @@ func (c *Client) Get(ctx context.Context, meta metadata) (*types.Response, error
 }
 
 func (c *Client) Set(ctx context.Context, meta metadata, value []byte) (*types.Response, error) {
-	err := c.client.Set(&memcache.Item{Key: meta.key, Value: value})
+	err := c.client.Set(&memcache.Item{Key: meta.key, Value: value, Expiration: int32(meta.ttlSeconds)})
 	if err != nil {
 		return nil, fmt.Errorf("failed to set key %s: %s", meta.key, err)
 	}
@@ -2,11 +2,12 @@ package memcached
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("cache.memcached").
 		SetDescription("Memcached Target").
 		SetName("Memcached").
@@ -58,5 +59,16 @@ func Connector() *common.Connector {
 				SetKind("string").
 				SetDescription("Set Memcached key").
 				SetMust(true),
+		).
+		AddMetadata(
+			common.NewMetadata().
+				SetName("ttl_seconds").
+				SetKind("int").
+				SetDescription("Set Memcached key expiry seconds, 0 for no expiry").
+				SetDefault("0").
+				SetMin(0).
+				SetMax(60 * 60 * 24 * 30).
+				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector)
 }
@@ -11,9 +11,13 @@ var methodsMap = map[string]string{
 	"delete": "delete",
 }
 
+// maxTTLSeconds is the max relative expiration of memcached, longer expirations are unix times
+const maxTTLSeconds = 60 * 60 * 24 * 30
+
 type metadata struct {
-	method string
-	key    string
+	method     string
+	key        string
+	ttlSeconds int
 }
 
 func parseMetadata(meta types.Metadata) (metadata, error) {
@@ -28,6 +32,10 @@ func parseMetadata(meta types.Metadata) (metadata, error) {
 	if err != nil {
 		return metadata{}, fmt.Errorf("error on parsing key value, %w", err)
 	}
+	m.ttlSeconds, err = meta.ParseIntWithRange("ttl_seconds", 0, 0, maxTTLSeconds)
+	if err != nil {
+		return metadata{}, fmt.Errorf("error on parsing ttl_seconds value, %w", err)
+	}
 
 	return m, nil
 }
@@ -13,7 +13,7 @@ import (
 )
 
 const (
-	setQuery                 = "local var1 = redis.pcall(\"HGET\", KEYS[1], \"version\"); if type(var1) == \"table\" then redis.call(\"DEL\", KEYS[1]); end; if not var1 or type(var1)==\"table\" or var1 == \"\" or var1 == ARGV[1] or ARGV[1] == \"0\" then redis.call(\"HSET\", KEYS[1], \"data\", ARGV[2]) return redis.call(\"HINCRBY\", KEYS[1], \"version\", 1) else return error(\"failed to set key \" .. KEYS[1]) end"
+	setQuery                 = "local var1 = redis.pcall(\"HGET\", KEYS[1], \"version\"); if type(var1) == \"table\" then redis.call(\"DEL\", KEYS[1]); end; if not var1 or type(var1)==\"table\" or var1 == \"\" or var1 == ARGV[1] or ARGV[1] == \"0\" then redis.call(\"HSET\", KEYS[1], \"data\", ARGV[2]) local version = redis.call(\"HINCRBY\", KEYS[1], \"version\", 1) if ARGV[3] == \"0\" then redis.call(\"PERSIST\", KEYS[1]) else redis.call(\"EXPIRE\", KEYS[1], ARGV[3]) end return version else return error(\"failed to set key \" .. KEYS[1]) end"
 	delQuery                 = "local var1 = redis.pcall(\"HGET\", KEYS[1], \"version\"); if not var1 or type(var1)==\"table\" or var1 == ARGV[1] or var1 == \"\" or ARGV[1] == \"0\" then return redis.call(\"DEL\", KEYS[1]) else return error(\"failed to delete \" .. KEYS[1]) end"
 	connectedSlavesReplicas  = "connected_slaves:"
 	infoReplicationDelimiter = "\r\n"
@@ -61,7 +61,7 @@ func (c *Client) Init(ctx context.Context, cfg config.Spec, log *logger.Logger)
 func (c *Client) Do(ctx context.Context, req *types.Request) (*types.Response, error) {
 	meta, err := parseMetadata(req.Metadata)
 	if err != nil {
-		return nil, err
+		return nil, types.NewInvalidRequestError(err)
 	}
 	switch meta.method {
 	case "get":
@@ -157,7 +157,7 @@ func (c *Client) Set(ctx context.Context, meta metadata, value []byte) (*types.R
 		meta.etag = 0
 	}
 
-	_, err := c.redis.DoContext(ctx, "EVAL", setQuery, 1, meta.key, meta.etag, value).Result()
+	_, err := c.redis.DoContext(ctx, "EVAL", setQuery, 1, meta.key, meta.etag, value, meta.ttlSeconds).Result()
 	if err != nil {
 		return nil, fmt.Errorf("failed to set key %s: %s", meta.key, err)
 	}
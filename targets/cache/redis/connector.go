@@ -2,11 +2,12 @@ package redis
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("cache.redis").
 		SetDescription("Redis Target").
 		SetName("Redis").
@@ -65,6 +66,16 @@ func Connector() *common.Connector {
 				SetOptions([]string{"strong", "eventual", ""}).
 				SetDefault("").
 				SetMust(false),
+		).
+		AddMetadata(
+			common.NewMetadata().
+				SetName("ttl_seconds").
+				SetKind("int").
+				SetDescription("Set Redis key expiry seconds, 0 for no expiry").
+				SetDefault("0").
+				SetMin(0).
+				SetMax(math.MaxInt32).
+				SetMust(false),
 		)
-
+	return secrets.MarkSecretProperties(connector, "url")
 }
@@ -28,6 +28,7 @@ type metadata struct {
 	etag        int
 	concurrency string
 	consistency string
+	ttlSeconds  int
 }
 
 func parseMetadata(meta types.Metadata) (metadata, error) {
@@ -55,5 +56,9 @@ func parseMetadata(meta types.Metadata) (metadata, error) {
 	if err != nil {
 		return metadata{}, fmt.Errorf("error on parsing consistency, %w", err)
 	}
+	m.ttlSeconds, err = meta.ParseIntWithRange("ttl_seconds", 0, 0, math.MaxInt32)
+	if err != nil {
+		return metadata{}, fmt.Errorf("error on parsing ttl_seconds value, %w", err)
+	}
 	return m, nil
 }
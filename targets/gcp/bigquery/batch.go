@@ -0,0 +1,80 @@
+package bigquery
+
+import (
+	"cloud.google.com/go/bigquery"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+)
+
+type insertBatch struct {
+	datasetID string
+	tableName string
+	indexes   []int
+	records   []record
+	offsets   []int
+}
+
+// DoBatch inserts the records of insert requests to the same table with a single put call, other requests are executed one by one
+func (c *Client) DoBatch(ctx context.Context, requests []*types.Request) ([]*types.Response, []error, error) {
+	responses := make([]*types.Response, len(requests))
+	errs := make([]error, len(requests))
+	batches := map[string]*insertBatch{}
+	for i, req := range requests {
+		meta, err := parseMetadata(req.Metadata)
+		if err != nil || meta.method != "insert" {
+			responses[i], errs[i] = c.Do(ctx, req)
+			continue
+		}
+		ir, err := newInsertRecord(req.Data)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		key := fmt.Sprintf("%s.%s", meta.datasetID, meta.tableName)
+		batch, ok := batches[key]
+		if !ok {
+			batch = &insertBatch{datasetID: meta.datasetID, tableName: meta.tableName}
+			batches[key] = batch
+		}
+		batch.indexes = append(batch.indexes, i)
+		batch.offsets = append(batch.offsets, len(batch.records))
+		batch.records = append(batch.records, ir.records...)
+	}
+	for _, batch := range batches {
+		c.insertBatch(ctx, batch, responses, errs)
+	}
+	return responses, errs, nil
+}
+
+func (c *Client) insertBatch(ctx context.Context, batch *insertBatch, responses []*types.Response, errs []error) {
+	err := c.client.Dataset(batch.datasetID).Table(batch.tableName).Inserter().Put(ctx, batch.records)
+	rowErrors := map[int]error{}
+	var multiErr bigquery.PutMultiError
+	if errors.As(err, &multiErr) {
+		for k := range multiErr {
+			rowErrors[multiErr[k].RowIndex] = &multiErr[k]
+		}
+	}
+	for j, i := range batch.indexes {
+		end := len(batch.records)
+		if j+1 < len(batch.offsets) {
+			end = batch.offsets[j+1]
+		}
+		var requestErr error
+		if err != nil && len(rowErrors) == 0 {
+			requestErr = err
+		}
+		for row := batch.offsets[j]; row < end && requestErr == nil; row++ {
+			requestErr = rowErrors[row]
+		}
+		if requestErr != nil {
+			errs[i] = requestErr
+			continue
+		}
+		responses[i] = types.NewResponse().
+			SetMetadataKeyValue("result", "ok").
+			SetMetadataKeyValue("insert_rows", fmt.Sprintf("%d", end-batch.offsets[j]))
+	}
+}
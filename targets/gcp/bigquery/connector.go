@@ -1,9 +1,12 @@
 package bigquery
 
-import "github.com/kubemq-hub/builder/connector/common"
+import (
+	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
+)
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("gcp.bigquery").
 		SetDescription("GCP Bigquery Target").
 		SetName("Big Query").
@@ -69,4 +72,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "credentials")
 }
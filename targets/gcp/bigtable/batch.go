@@ -0,0 +1,58 @@
+package bigtable
+
+import (
+	"cloud.google.com/go/bigtable"
+	"context"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+)
+
+type writeBatch struct {
+	tableName string
+	indexes   []int
+	rowKeys   []string
+	muts      []*bigtable.Mutation
+}
+
+// DoBatch applies write requests to the same table with a single bulk apply call, other requests are executed one by one
+func (c *Client) DoBatch(ctx context.Context, requests []*types.Request) ([]*types.Response, []error, error) {
+	responses := make([]*types.Response, len(requests))
+	errs := make([]error, len(requests))
+	batches := map[string]*writeBatch{}
+	timestamp := bigtable.Now()
+	for i, req := range requests {
+		meta, err := parseMetadata(req.Metadata)
+		if err != nil || meta.method != "write" {
+			responses[i], errs[i] = c.Do(ctx, req)
+			continue
+		}
+		rowKey, mut, err := c.getRowMutation(meta.columnFamily, timestamp, req.Data)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		batch, ok := batches[meta.tableName]
+		if !ok {
+			batch = &writeBatch{tableName: meta.tableName}
+			batches[meta.tableName] = batch
+		}
+		batch.indexes = append(batch.indexes, i)
+		batch.rowKeys = append(batch.rowKeys, rowKey)
+		batch.muts = append(batch.muts, mut)
+	}
+	for _, batch := range batches {
+		rowErrs, err := c.client.Open(batch.tableName).ApplyBulk(ctx, batch.rowKeys, batch.muts)
+		for j, i := range batch.indexes {
+			switch {
+			case err != nil:
+				errs[i] = err
+			case j < len(rowErrs) && rowErrs[j] != nil:
+				errs[i] = fmt.Errorf("error writing row %s, %w", batch.rowKeys[j], rowErrs[j])
+			default:
+				responses[i] = types.NewResponse().
+					SetMetadataKeyValue("result", "ok")
+			}
+		}
+	}
+	return responses, errs, nil
+}
@@ -60,7 +60,7 @@ func (c *Client) Init(ctx context.Context, cfg config.Spec, log *logger.Logger)
 func (c *Client) Do(ctx context.Context, req *types.Request) (*types.Response, error) {
 	meta, err := parseMetadata(req.Metadata)
 	if err != nil {
-		return nil, err
+		return nil, types.NewInvalidRequestError(err)
 	}
 	switch meta.method {
 	case "write":
@@ -230,11 +230,24 @@ func (c *Client) readAllRowsByColumnFilter(ctx context.Context, meta metadata, b
 
 func (c *Client) writeRow(ctx context.Context, meta metadata, body []byte) (*types.Response, error) {
 	tbl := c.client.Open(meta.tableName)
-	timestamp := bigtable.Now()
+	rowKey, mut, err := c.getRowMutation(meta.columnFamily, bigtable.Now(), body)
+	if err != nil {
+		return nil, err
+	}
+	err = tbl.Apply(ctx, rowKey, mut)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewResponse().
+			SetMetadataKeyValue("result", "ok"),
+		nil
+}
+
+func (c *Client) getRowMutation(columnFamily string, timestamp bigtable.Timestamp, body []byte) (string, *bigtable.Mutation, error) {
 	mut := bigtable.NewMutation()
 	m, err := c.getSingleColumnFromBody(body)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 	rowKey := ""
 	for k, v := range m {
@@ -244,25 +257,19 @@ func (c *Client) writeRow(ctx context.Context, meta metadata, body []byte) (*typ
 			buf := new(bytes.Buffer)
 			b, err := json.Marshal(v)
 			if err != nil {
-				return nil, err
+				return "", nil, err
 			}
 			err = binary.Write(buf, binary.BigEndian, b)
 			if err != nil {
-				return nil, err
+				return "", nil, err
 			}
-			mut.Set(meta.columnFamily, k, timestamp, buf.Bytes())
+			mut.Set(columnFamily, k, timestamp, buf.Bytes())
 		}
 	}
 	if len(rowKey) == 0 {
-		return nil, fmt.Errorf("missing set_row_key value")
+		return "", nil, fmt.Errorf("missing set_row_key value")
 	}
-	err = tbl.Apply(ctx, rowKey, mut)
-	if err != nil {
-		return nil, err
-	}
-	return types.NewResponse().
-			SetMetadataKeyValue("result", "ok"),
-		nil
+	return rowKey, mut, nil
 }
 
 func (c *Client) writeBatch(ctx context.Context, meta metadata, body []byte) (*types.Response, error) {
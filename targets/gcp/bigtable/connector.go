@@ -2,10 +2,11 @@ package bigtable
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("gcp.bigtable").
 		SetDescription("GCP Bigtable Target").
 		SetName("Big Table").
@@ -79,4 +80,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "credentials")
 }
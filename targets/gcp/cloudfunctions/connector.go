@@ -2,10 +2,11 @@ package cloudfunctions
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("gcp.cloudfunctions").
 		SetDescription("GCP Cloud Functions Target").
 		SetName("Cloud Functions").
@@ -62,4 +63,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "credentials")
 }
@@ -2,10 +2,11 @@ package firebase
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("gcp.firebase").
 		SetDescription("GCP Firebase Target").
 		SetName("Firebase").
@@ -70,7 +71,7 @@ func Connector() *common.Connector {
 				SetName("method").
 				SetKind("string").
 				SetDescription("Set GCP Firebase execution method").
-				SetOptions([]string{"custom_token", "verify_token", "retrieve_user", "create_user", "delete_user", "delete_multiple_users", "list_users", "get_db", "delete_db", "set_db", "send_message", "send_multi"}).
+				SetOptions([]string{"custom_token", "verify_token", "retrieve_user", "create_user", "update_user", "delete_user", "delete_multiple_users", "list_users", "get_db", "update_db", "delete_db", "set_db", "send_message", "send_multi"}).
 				SetDefault("create_user").
 				SetMust(true),
 		).
@@ -131,4 +132,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "credentials")
 }
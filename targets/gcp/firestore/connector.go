@@ -2,10 +2,11 @@ package firestore
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("gcp.firestore").
 		SetDescription("GCP Firestore Target").
 		SetName("Firestore").
@@ -55,4 +56,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "credentials")
 }
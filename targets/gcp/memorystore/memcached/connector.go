@@ -2,11 +2,12 @@ package memcached
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("gcp.cache.memcached").
 		SetDescription("GCP Memory Store Memcached Target").
 		SetName("Memcached").
@@ -59,4 +60,5 @@ func Connector() *common.Connector {
 				SetDescription("Set Memcached key").
 				SetMust(true),
 		)
+	return secrets.MarkSecretProperties(connector)
 }
@@ -50,7 +50,7 @@ func (c *Client) Init(ctx context.Context, cfg config.Spec, log *logger.Logger)
 func (c *Client) Do(ctx context.Context, request *types.Request) (*types.Response, error) {
 	eventMetadata, err := parseMetadata(request.Metadata, c.opts)
 	if err != nil {
-		return nil, err
+		return nil, types.NewInvalidRequestError(err)
 	}
 	t := c.client.Topic(eventMetadata.topicID)
 	result := t.Publish(ctx, &pubsub.Message{
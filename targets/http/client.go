@@ -8,10 +8,13 @@ import (
 	"github.com/kubemq-hub/builder/connector/common"
 	"github.com/kubemq-hub/kubemq-targets/config"
 	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/pkg/tracing"
 	"github.com/kubemq-hub/kubemq-targets/types"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Client struct {
@@ -66,10 +69,11 @@ func (c *Client) Init(ctx context.Context, cfg config.Spec, log *logger.Logger)
 func (c *Client) Do(ctx context.Context, req *types.Request) (*types.Response, error) {
 	meta, err := parseMetadata(req.Metadata)
 	if err != nil {
-		return nil, err
+		return nil, types.NewInvalidRequestError(err)
 	}
 	httpReq := c.client.R().
 		SetHeaders(meta.headers).
+		SetHeaders(tracing.Headers(ctx)).
 		SetContext(ctx)
 
 	if req.Data != nil {
@@ -85,7 +89,44 @@ func (c *Client) Do(ctx context.Context, req *types.Request) (*types.Response, e
 	if err != nil {
 		return nil, err
 	}
-	return tr, resp.RawResponse.Body.Close()
+	err = resp.RawResponse.Body.Close()
+	if statusErr := newStatusError(resp.RawResponse); statusErr != nil {
+		return tr, statusErr
+	}
+	return tr, err
+}
+
+// newStatusError returns a classified error for throttled (429) and unavailable (503) responses with the server retry after hint,
+// the response is returned to the caller with the error. Other responses are returned to the caller as is
+func newStatusError(hr *http.Response) error {
+	switch hr.StatusCode {
+	case http.StatusTooManyRequests:
+		return types.NewThrottledError(fmt.Errorf("http request throttled, status: %s", hr.Status), parseRetryAfter(hr.Header.Get("Retry-After")))
+	case http.StatusServiceUnavailable:
+		return &types.ClassifiedError{
+			Class:      types.ErrorClassTransient,
+			RetryAfter: parseRetryAfter(hr.Header.Get("Retry-After")),
+			Err:        fmt.Errorf("http service unavailable, status: %s", hr.Status),
+		}
+	default:
+		return nil
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value of delay seconds or an http date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
 }
 
 func newResultFromHttpResponse(hr *http.Response) (*types.Response, error) {
@@ -58,6 +58,13 @@ func (m *mockHttpServer) start() {
 		}
 		return c.JSON(200, p)
 	})
+	m.echo.GET("/throttled", func(c echo.Context) error {
+		c.Response().Header().Set("Retry-After", "2")
+		return c.JSON(429, nil)
+	})
+	m.echo.GET("/unavailable", func(c echo.Context) error {
+		return c.JSON(503, nil)
+	})
 	m.echo.GET("/get", func(c echo.Context) error {
 		if m.getError {
 			return c.JSON(500, nil)
@@ -75,12 +82,15 @@ func (m *mockHttpServer) stop(ctx context.Context) {
 }
 func TestClient_Do(t *testing.T) {
 	tests := []struct {
-		name    string
-		mock    *mockHttpServer
-		cfg     config.Spec
-		request *types.Request
-		want    *types.Response
-		wantErr bool
+		name       string
+		mock       *mockHttpServer
+		cfg        config.Spec
+		request    *types.Request
+		want       *types.Response
+		wantErr    bool
+		wantClass  types.ErrorClass
+		retryAfter time.Duration
+		wantCode   string
 	}{
 		{
 			name: "valid request - post",
@@ -260,6 +270,47 @@ func TestClient_Do(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "valid request - throttled",
+			mock: &mockHttpServer{
+				port: "30006",
+			},
+			cfg: config.Spec{
+				Name: "http",
+				Kind: "http",
+				Properties: map[string]string{
+					"auth_type": "no_auth",
+				},
+			},
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "get").
+				SetMetadataKeyValue("url", "http://localhost:30006/throttled"),
+			want:       nil,
+			wantErr:    true,
+			wantClass:  types.ErrorClassThrottled,
+			retryAfter: 2 * time.Second,
+			wantCode:   "429",
+		},
+		{
+			name: "valid request - service unavailable",
+			mock: &mockHttpServer{
+				port: "30007",
+			},
+			cfg: config.Spec{
+				Name: "http",
+				Kind: "http",
+				Properties: map[string]string{
+					"auth_type": "no_auth",
+				},
+			},
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "get").
+				SetMetadataKeyValue("url", "http://localhost:30007/unavailable"),
+			want:      nil,
+			wantErr:   true,
+			wantClass: types.ErrorClassTransient,
+			wantCode:  "503",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -274,6 +325,12 @@ func TestClient_Do(t *testing.T) {
 			got, err := c.Do(ctx, tt.request)
 			if tt.wantErr {
 				require.Error(t, err)
+				if tt.wantClass != "" {
+					require.Equal(t, tt.wantClass, types.ErrorClassOf(err))
+					require.Equal(t, tt.retryAfter, types.RetryAfterOf(err))
+					require.NotNil(t, got)
+					require.Equal(t, tt.wantCode, got.Metadata["code"])
+				}
 				return
 			}
 			require.NoError(t, err)
@@ -2,10 +2,11 @@ package http
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("http").
 		SetDescription("HTTP/Rest Target").
 		SetName("HTTP").
@@ -131,4 +132,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(false),
 		)
+	return secrets.MarkSecretProperties(connector, "password", "token", "client_private_key")
 }
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"github.com/kubemq-hub/builder/connector/common"
 	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/pkg/tracing"
 	"strconv"
 
 	kafka "github.com/Shopify/sarama"
@@ -64,11 +65,15 @@ func (c *Client) Do(ctx context.Context, request *types.Request) (*types.Respons
 
 	m, err := parseMetadata(request.Metadata, c.opts)
 	if err != nil {
-		return nil, err
+		return nil, types.NewInvalidRequestError(err)
 	}
 
+	headers := m.Headers
+	for key, value := range tracing.Headers(ctx) {
+		headers = append(headers, kafka.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
 	partition, offset, err := c.producer.SendMessage(&kafka.ProducerMessage{
-		Headers: m.Headers,
+		Headers: headers,
 		Key:     kafka.ByteEncoder(m.Key),
 		Value:   kafka.ByteEncoder(request.Data),
 		Topic:   c.opts.topic,
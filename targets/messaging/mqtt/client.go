@@ -58,7 +58,7 @@ func (c *Client) Do(ctx context.Context, req *types.Request) (*types.Response, e
 		var err error
 		meta, err = parseMetadata(req.Metadata)
 		if err != nil {
-			return nil, err
+			return nil, types.NewInvalidRequestError(err)
 		}
 	}
 	token := c.client.Publish(meta.topic, byte(meta.qos), false, req.Data)
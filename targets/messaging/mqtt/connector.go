@@ -2,10 +2,11 @@ package mqtt
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("messaging.mqtt").
 		SetDescription("MQTT Messaging Target").
 		SetName("MQTT").
@@ -82,4 +83,5 @@ func Connector() *common.Connector {
 				SetMax(2).
 				SetDefault("0"),
 		)
+	return secrets.MarkSecretProperties(connector, "password")
 }
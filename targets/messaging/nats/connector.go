@@ -2,10 +2,11 @@ package nats
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("messaging.nats").
 		SetDescription("nats source properties").
 		SetName("NATS").
@@ -85,4 +86,5 @@ func Connector() *common.Connector {
 			SetMust(true).
 			SetDefault(""),
 		)
+	return secrets.MarkSecretProperties(connector, "password", "token", "cert_key")
 }
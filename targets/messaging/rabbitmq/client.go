@@ -6,6 +6,7 @@ import (
 	"github.com/kubemq-hub/builder/connector/common"
 	"github.com/kubemq-hub/kubemq-targets/config"
 	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/pkg/tracing"
 	"github.com/kubemq-hub/kubemq-targets/types"
 	"github.com/streadway/amqp"
 	"sync"
@@ -67,7 +68,7 @@ func (c *Client) Do(ctx context.Context, req *types.Request) (*types.Response, e
 		var err error
 		meta, err = parseMetadata(req.Metadata)
 		if err != nil {
-			return nil, err
+			return nil, types.NewInvalidRequestError(err)
 		}
 	}
 	return c.Publish(ctx, meta, req.Data)
@@ -82,6 +83,9 @@ func (c *Client) Publish(ctx context.Context, meta metadata, data []byte) (*type
 		}
 	}
 	msg := meta.amqpMessage(data)
+	for key, value := range tracing.Headers(ctx) {
+		msg.Headers[key] = value
+	}
 	err := c.channel.Publish(meta.exchange, meta.queue, meta.mandatory, meta.immediate, msg)
 	if err != nil {
 		c.isConnected = false
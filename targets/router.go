@@ -0,0 +1,226 @@
+package targets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/config"
+	"github.com/kubemq-hub/kubemq-targets/pkg/expression"
+	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"strings"
+	"sync"
+)
+
+const (
+	RouterKind = "router"
+
+	RoutingModeFirst     = "first"
+	RoutingModeAll       = "all"
+	RoutingModeBroadcast = "broadcast"
+)
+
+var routingModeMap = map[string]string{
+	"first":     RoutingModeFirst,
+	"all":       RoutingModeAll,
+	"broadcast": RoutingModeBroadcast,
+	"":          RoutingModeFirst,
+}
+
+type route struct {
+	name      string
+	condition *expression.Expression
+	target    Target
+}
+
+type routeResponse struct {
+	Metadata types.Metadata  `json:"metadata"`
+	Data     json.RawMessage `json:"data"`
+	IsError  bool            `json:"is_error"`
+	Error    string          `json:"error"`
+}
+
+// Router is a target which dispatches each request to one or more route targets
+type Router struct {
+	routesCfg    []config.RouteConfig
+	mode         string
+	routes       []*route
+	defaultRoute *route
+	log          *logger.Logger
+}
+
+func NewRouter(routes []config.RouteConfig) *Router {
+	return &Router{
+		routesCfg: routes,
+	}
+}
+
+func InitRouter(ctx context.Context, routes []config.RouteConfig, properties types.Metadata, log *logger.Logger) (Target, error) {
+	router := NewRouter(routes)
+	if err := router.Init(ctx, config.Spec{Kind: RouterKind, Properties: properties}, log); err != nil {
+		return nil, err
+	}
+	return router, nil
+}
+
+func (r *Router) Init(ctx context.Context, cfg config.Spec, log *logger.Logger) error {
+	r.log = log
+	if r.log == nil {
+		r.log = logger.NewLogger(cfg.Kind)
+	}
+	var err error
+	r.mode, err = cfg.Properties.ParseStringMap("routing_mode", routingModeMap)
+	if err != nil {
+		return fmt.Errorf("invalid routing mode value, %w", err)
+	}
+	for _, routeCfg := range r.routesCfg {
+		rt := &route{
+			name: routeCfg.Name,
+		}
+		if routeCfg.Condition != "" {
+			rt.condition, err = expression.New(routeCfg.Name, routeCfg.Condition)
+			if err != nil {
+				_ = r.Stop()
+				return fmt.Errorf("invalid condition on route %s, %w", routeCfg.Name, err)
+			}
+		}
+		rt.target, err = Init(ctx, routeCfg.Target, r.log)
+		if err != nil {
+			_ = r.Stop()
+			return fmt.Errorf("error loading target conntector on route %s, %w", routeCfg.Name, err)
+		}
+		if routeCfg.Default {
+			r.defaultRoute = rt
+		}
+		r.routes = append(r.routes, rt)
+	}
+	return nil
+}
+
+func (r *Router) Connector() *common.Connector {
+	return common.NewConnector().
+		SetKind(RouterKind).
+		SetDescription("Routes requests to multiple targets").
+		SetName("Router")
+}
+
+func (r *Router) selectRoutes(request *types.Request) []*route {
+	if r.mode == RoutingModeBroadcast {
+		return r.routes
+	}
+	ec := expression.NewContext(request.Metadata, request.Data)
+	var selected []*route
+	for _, rt := range r.routes {
+		if rt.condition == nil {
+			continue
+		}
+		match, err := rt.condition.Match(ec)
+		if err != nil {
+			r.log.Debugf("route %s condition evaluation failed, skipping route, %s", rt.name, err.Error())
+			continue
+		}
+		if match {
+			selected = append(selected, rt)
+			if r.mode == RoutingModeFirst {
+				break
+			}
+		}
+	}
+	if len(selected) == 0 && r.defaultRoute != nil {
+		selected = append(selected, r.defaultRoute)
+	}
+	return selected
+}
+
+func (r *Router) Do(ctx context.Context, request *types.Request) (*types.Response, error) {
+	selected := r.selectRoutes(request)
+	switch len(selected) {
+	case 0:
+		return nil, fmt.Errorf("no matching route found for request")
+	case 1:
+		return selected[0].target.Do(ctx, request)
+	}
+	responses := make([]*routeResponse, len(selected))
+	wg := sync.WaitGroup{}
+	wg.Add(len(selected))
+	for i, rt := range selected {
+		go func(i int, rt *route) {
+			defer wg.Done()
+			responses[i] = toRouteResponse(rt.target.Do(ctx, request))
+		}(i, rt)
+	}
+	wg.Wait()
+	return aggregateResponses(selected, responses)
+}
+
+func toRouteResponse(resp *types.Response, err error) *routeResponse {
+	if err != nil {
+		return &routeResponse{
+			Metadata: types.NewMetadata(),
+			IsError:  true,
+			Error:    err.Error(),
+		}
+	}
+	if resp == nil {
+		return &routeResponse{
+			Metadata: types.NewMetadata(),
+		}
+	}
+	rr := &routeResponse{
+		Metadata: resp.Metadata,
+		IsError:  resp.IsError,
+		Error:    resp.Error,
+	}
+	if len(resp.Data) > 0 {
+		if json.Valid(resp.Data) {
+			rr.Data = resp.Data
+		} else {
+			rr.Data, _ = json.Marshal(string(resp.Data))
+		}
+	}
+	return rr
+}
+
+// aggregateResponses returns one response keyed by route name, an error is returned only when all routes failed
+func aggregateResponses(selected []*route, responses []*routeResponse) (*types.Response, error) {
+	aggregated := map[string]*routeResponse{}
+	var names, failed []string
+	for i, rt := range selected {
+		aggregated[rt.name] = responses[i]
+		names = append(names, rt.name)
+		if responses[i].IsError {
+			failed = append(failed, rt.name)
+		}
+	}
+	data, err := json.Marshal(aggregated)
+	if err != nil {
+		return nil, fmt.Errorf("error aggregating routes responses, %w", err)
+	}
+	if len(failed) == len(selected) {
+		return nil, fmt.Errorf("all routes failed: %s", strings.Join(failed, ","))
+	}
+	resp := types.NewResponse().
+		SetMetadataKeyValue("routes", strings.Join(names, ",")).
+		SetData(data)
+	if len(failed) > 0 {
+		resp.SetError(fmt.Errorf("routes failed: %s", strings.Join(failed, ",")))
+	}
+	return resp, nil
+}
+
+func (r *Router) Stop() error {
+	var errs []string
+	for _, rt := range r.routes {
+		if rt.target == nil {
+			continue
+		}
+		if err := rt.target.Stop(); err != nil {
+			errs = append(errs, fmt.Sprintf("route %s: %s", rt.name, err.Error()))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error stopping routes targets, %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package targets
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/kubemq-hub/kubemq-targets/config"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRouter_Do(t *testing.T) {
+	routes := []config.RouteConfig{
+		{
+			Name:      "orders",
+			Condition: `{{eq .Metadata.type "order"}}`,
+			Target:    config.Spec{Kind: "echo"},
+		},
+		{
+			Name:      "vip",
+			Condition: `{{eq .Data.level "vip"}}`,
+			Target:    config.Spec{Kind: "echo"},
+		},
+		{
+			Name:    "default",
+			Default: true,
+			Target:  config.Spec{Kind: "echo"},
+		},
+	}
+	tests := []struct {
+		name       string
+		mode       string
+		routes     []config.RouteConfig
+		request    *types.Request
+		wantRoutes []string
+		wantErr    bool
+	}{
+		{
+			name:       "first - metadata match",
+			mode:       "first",
+			routes:     routes,
+			request:    types.NewRequest().SetMetadataKeyValue("type", "order").SetData([]byte(`{"level":"vip"}`)),
+			wantRoutes: nil,
+			wantErr:    false,
+		},
+		{
+			name:       "all - metadata and data match",
+			mode:       "all",
+			routes:     routes,
+			request:    types.NewRequest().SetMetadataKeyValue("type", "order").SetData([]byte(`{"level":"vip"}`)),
+			wantRoutes: []string{"orders", "vip"},
+			wantErr:    false,
+		},
+		{
+			name:       "broadcast",
+			mode:       "broadcast",
+			routes:     routes,
+			request:    types.NewRequest().SetData([]byte(`data`)),
+			wantRoutes: []string{"orders", "vip", "default"},
+			wantErr:    false,
+		},
+		{
+			name:       "default route",
+			mode:       "all",
+			routes:     routes,
+			request:    types.NewRequest().SetData([]byte(`data`)),
+			wantRoutes: nil,
+			wantErr:    false,
+		},
+		{
+			name:    "no matching route",
+			mode:    "first",
+			routes:  routes[:2],
+			request: types.NewRequest().SetData([]byte(`data`)),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			router, err := InitRouter(ctx, tt.routes, map[string]string{"routing_mode": tt.mode}, nil)
+			require.NoError(t, err)
+			defer func() {
+				_ = router.Stop()
+			}()
+			resp, err := router.Do(ctx, tt.request)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, resp)
+			if tt.wantRoutes == nil {
+				require.EqualValues(t, tt.request.Data, resp.Data)
+				return
+			}
+			aggregated := map[string]*routeResponse{}
+			require.NoError(t, json.Unmarshal(resp.Data, &aggregated))
+			require.Equal(t, len(tt.wantRoutes), len(aggregated))
+			for _, name := range tt.wantRoutes {
+				require.Contains(t, aggregated, name)
+			}
+		})
+	}
+}
+
+func TestRouter_Init(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := InitRouter(ctx, []config.RouteConfig{{Name: "r", Condition: "{{.Data", Target: config.Spec{Kind: "echo"}}}, nil, nil)
+	require.Error(t, err)
+	_, err = InitRouter(ctx, []config.RouteConfig{{Name: "r", Default: true, Target: config.Spec{Kind: "bad-kind"}}}, nil, nil)
+	require.Error(t, err)
+	_, err = InitRouter(ctx, nil, map[string]string{"routing_mode": "bad-mode"}, nil)
+	require.Error(t, err)
+}
@@ -55,7 +55,7 @@ func readBody(data io.ReadCloser) ([]byte, error) {
 func (c *Client) Do(ctx context.Context, req *types.Request) (*types.Response, error) {
 	meta, err := parseMetadata(req.Metadata)
 	if err != nil {
-		return nil, err
+		return nil, types.NewInvalidRequestError(err)
 	}
 	url := fmt.Sprintf("%s/%s", c.opts.gateway, meta.topic)
 	resp, err := c.client.R().SetContext(ctx).SetBody(req.Data).Post(url)
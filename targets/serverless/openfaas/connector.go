@@ -2,10 +2,11 @@ package openfaas
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("serverless.openfaas").
 		SetDescription("Openfaas Target").
 		SetName("OpenFaas").
@@ -45,4 +46,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(true),
 		)
+	return secrets.MarkSecretProperties(connector, "password")
 }
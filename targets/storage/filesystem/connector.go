@@ -2,10 +2,11 @@ package filesystem
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("storage.filesystem").
 		SetDescription("Local Filesystem Target").
 		SetName("File System").
@@ -46,4 +47,5 @@ func Connector() *common.Connector {
 				SetDefault("").
 				SetMust(true),
 		)
+	return secrets.MarkSecretProperties(connector)
 }
@@ -2,10 +2,11 @@ package hdfs
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("storage.hdfs").
 		SetDescription("Hadoop Target").
 		SetName("HDFS").
@@ -61,5 +62,5 @@ func Connector() *common.Connector {
 				SetMust(false).
 				SetDefault("0777"),
 		)
-
+	return secrets.MarkSecretProperties(connector)
 }
@@ -2,11 +2,12 @@ package consulkv
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("stores.consulkv").
 		SetDescription("consulkv source properties").
 		SetName("Consul").
@@ -190,4 +191,5 @@ func Connector() *common.Connector {
 				SetMin(1).
 				SetMax(math.MaxInt32),
 		)
+	return secrets.MarkSecretProperties(connector, "token", "cert_key")
 }
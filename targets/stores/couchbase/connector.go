@@ -2,11 +2,12 @@ package couchbase
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("stores.couchbase").
 		SetDescription("Couchbase Target").
 		SetName("Couchbase").
@@ -93,4 +94,5 @@ func Connector() *common.Connector {
 				SetDescription("Couchbase key to set get or delete").
 				SetMust(true),
 		)
+	return secrets.MarkSecretProperties(connector, "password")
 }
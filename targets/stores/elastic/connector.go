@@ -2,10 +2,11 @@ package elastic
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("stores.elasticsearch").
 		SetDescription("Elastic Search Target").
 		SetName("Elasticsearch").
@@ -70,4 +71,5 @@ func Connector() *common.Connector {
 				SetDescription("Select Elastic document id").
 				SetMust(true),
 		)
+	return secrets.MarkSecretProperties(connector, "password")
 }
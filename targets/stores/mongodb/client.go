@@ -130,7 +130,7 @@ func (c *Client) getMongoDBClient(ctx context.Context) (*mongo.Client, error) {
 func (c *Client) Do(ctx context.Context, req *types.Request) (*types.Response, error) {
 	meta, err := parseMetadata(req.Metadata)
 	if err != nil {
-		return nil, err
+		return nil, types.NewInvalidRequestError(err)
 	}
 	switch meta.method {
 	case "get_by_key":
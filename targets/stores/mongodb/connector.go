@@ -2,11 +2,12 @@ package mongodb
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("stores.mongodb").
 		SetDescription("MongoDB Target").
 		SetName("MongoDB").
@@ -97,7 +98,7 @@ func Connector() *common.Connector {
 				SetKind("string").
 				SetDescription("Set MongoDB execution method").
 				SetOptions([]string{"get_by_key", "set_by_key", "delete_by_key", "find", "find_many",
-					"insert", "insert_many", "update", "update_many", "delete_one",
+					"insert", "insert_many", "update", "update_many", "delete",
 					"delete_many", "aggregate", "distinct",
 				}).
 				SetDefault("get").
@@ -124,5 +125,5 @@ func Connector() *common.Connector {
 				SetDescription("Set Upsert in update mode").
 				SetMust(false),
 		)
-
+	return secrets.MarkSecretProperties(connector, "password")
 }
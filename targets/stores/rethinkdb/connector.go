@@ -2,11 +2,12 @@ package rethinkdb
 
 import (
 	"github.com/kubemq-hub/builder/connector/common"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"math"
 )
 
 func Connector() *common.Connector {
-	return common.NewConnector().
+	connector := common.NewConnector().
 		SetKind("stores.rethinkdb").
 		SetDescription("Rethinkdb Target").
 		SetName("RethinkDB").
@@ -164,4 +165,5 @@ func Connector() *common.Connector {
 				SetDescription("Set Rethinkdb db name").
 				SetMust(true),
 		)
+	return secrets.MarkSecretProperties(connector, "password", "auth_key", "cert_key")
 }
@@ -0,0 +1,225 @@
+package sqlcore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"strconv"
+	"sync"
+)
+
+// TxFunc executes fn in a transaction of db, committing it when fn succeeds
+type TxFunc func(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error
+
+// Client executes the query, exec, transaction, insert and migrate requests of the sql targets, each target opens its
+// driver database and sets the client dialect
+type Client struct {
+	db              *sql.DB
+	dialect         *Dialect
+	methods         map[string]string
+	executeTx       TxFunc
+	mu              sync.Mutex
+	tables          map[string]*table
+	migrations      []Migration
+	migrationsTable string
+}
+
+func NewClient(db *sql.DB, dialect *Dialect) *Client {
+	return &Client{
+		db:              db,
+		dialect:         dialect,
+		methods:         methodsMap,
+		executeTx:       executeTx,
+		tables:          map[string]*table{},
+		migrationsTable: defaultMigrationsTable,
+	}
+}
+
+// Open opens a database of the driver and verifies the connection
+func Open(ctx context.Context, driverName, dataSourceName string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	err = db.PingContext(ctx)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// SetMethods sets the request methods supported by the target, all methods are supported by default
+func (c *Client) SetMethods(methods ...string) *Client {
+	c.methods = map[string]string{}
+	for _, method := range methods {
+		c.methods[method] = method
+	}
+	return c
+}
+
+// SetTxFunc sets the transaction executor, for databases which require client side transaction retries
+func (c *Client) SetTxFunc(fn TxFunc) *Client {
+	c.executeTx = fn
+	return c
+}
+
+func (c *Client) DB() *sql.DB {
+	return c.db
+}
+
+func (c *Client) Do(ctx context.Context, req *types.Request) (*types.Response, error) {
+	meta, err := parseMetadata(req.Metadata, c.methods)
+	if err != nil {
+		return nil, types.NewInvalidRequestError(err)
+	}
+	switch meta.Method {
+	case "query":
+		return c.Query(ctx, meta, req.Data)
+	case "exec":
+		return c.Exec(ctx, meta, req.Data)
+	case "transaction":
+		return c.Transaction(ctx, meta, req.Data)
+	case "insert":
+		return c.Insert(ctx, meta, req.Data)
+	case "upsert":
+		return c.Upsert(ctx, meta, req.Data)
+	case "bulk_insert":
+		return c.BulkInsert(ctx, meta, req.Data)
+	case "migrate":
+		return c.Migrate(ctx, meta, req.Data)
+	}
+	return nil, errors.New("invalid method type")
+}
+
+func (c *Client) Exec(ctx context.Context, meta Metadata, value []byte) (*types.Response, error) {
+	stmts, err := ParseStatements(value, meta.Params, c.dialect)
+	if err != nil {
+		return nil, types.NewInvalidRequestError(err)
+	}
+	if stmts == nil {
+		return nil, fmt.Errorf("no exec statement found")
+	}
+	for i, stmt := range stmts {
+		_, err := c.db.ExecContext(ctx, stmt.Query, stmt.Args...)
+		if err != nil {
+			return nil, fmt.Errorf("error on statement %d, %w", i, err)
+		}
+	}
+	return types.NewResponse().
+			SetMetadataKeyValue("result", "ok"),
+		nil
+}
+
+func (c *Client) Transaction(ctx context.Context, meta Metadata, value []byte) (*types.Response, error) {
+	stmts, err := ParseStatements(value, meta.Params, c.dialect)
+	if err != nil {
+		return nil, types.NewInvalidRequestError(err)
+	}
+	if stmts == nil {
+		return nil, fmt.Errorf("no transaction statements found")
+	}
+	err = c.executeTx(ctx, c.db, &sql.TxOptions{
+		Isolation: meta.IsolationLevel,
+		ReadOnly:  false,
+	}, func(tx *sql.Tx) error {
+		for i, stmt := range stmts {
+			_, err := tx.ExecContext(ctx, stmt.Query, stmt.Args...)
+			if err != nil {
+				return fmt.Errorf("error on statement %d, %w", i, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return types.NewResponse().
+			SetMetadataKeyValue("result", "ok"),
+		nil
+}
+
+func (c *Client) Query(ctx context.Context, meta Metadata, value []byte) (*types.Response, error) {
+	stmt, err := ParseStatement(value, meta.Params, c.dialect)
+	if err != nil {
+		return nil, types.NewInvalidRequestError(err)
+	}
+	if stmt.Query == "" {
+		return nil, fmt.Errorf("no query statement found")
+	}
+	offset, err := parseCursor(meta.Cursor, stmt)
+	if err != nil {
+		return nil, types.NewInvalidRequestError(err)
+	}
+	rows, err := c.db.QueryContext(ctx, stmt.Query, stmt.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := resultColumns(rows)
+	if err != nil {
+		return nil, err
+	}
+	res, err := encodeRows(rows, columns, meta.Format, meta.IncludeNulls, offset, meta.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := types.NewResponse().
+		SetData(res.data).
+		SetMetadataKeyValue("result", "ok").
+		SetMetadataKeyValue("rows", strconv.Itoa(res.count))
+	if meta.IncludeColumns {
+		columnsData, err := json.Marshal(res.columns)
+		if err != nil {
+			return nil, err
+		}
+		resp.SetMetadataKeyValue("columns", string(columnsData))
+	}
+	if res.more {
+		resp.SetMetadataKeyValue("next_cursor", newCursor(stmt, offset+res.count))
+	}
+	return resp, nil
+}
+
+func (c *Client) Close() error {
+	if c.db != nil {
+		return c.db.Close()
+	}
+	return nil
+}
+
+func executeTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	return executeConnTx(ctx, db, opts, fn)
+}
+
+// executeDBTx executes fn in a transaction of the client database with the client transaction executor, or in a
+// transaction of a connection of the database
+func (c *Client) executeDBTx(ctx context.Context, db sqlDB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	if sqlDB(c.db) == db {
+		return c.executeTx(ctx, c.db, opts, fn)
+	}
+	return executeConnTx(ctx, db, opts, fn)
+}
+
+func executeConnTx(ctx context.Context, db sqlDB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			err = fmt.Errorf("transaction aborted, %v", r)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		if rollBackErr := tx.Rollback(); rollBackErr != nil {
+			return rollBackErr
+		}
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,703 @@
+package sqlcore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDriver records the executed statements and returns the same result rows for all queries
+type fakeDriver struct {
+	sync.Mutex
+	log []string
+	// versions of the migrations table, which does not exist when nil
+	versions []int64
+	// lockNotTaken fails the migrations lock statements
+	lockNotTaken bool
+}
+
+func (d *fakeDriver) record(entry string) {
+	d.Lock()
+	defer d.Unlock()
+	d.log = append(d.log, entry)
+}
+
+func (d *fakeDriver) entries() []string {
+	d.Lock()
+	defer d.Unlock()
+	var entries []string
+	return append(entries, d.log...)
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{driver: d}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.driver.record(fmt.Sprintf("begin %s", sql.IsolationLevel(opts.Isolation).String()))
+	return &fakeTx{driver: c.driver}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	var values []string
+	for _, arg := range args {
+		if _, ok := arg.Value.(time.Time); ok {
+			values = append(values, "time")
+			continue
+		}
+		values = append(values, fmt.Sprintf("%v", arg.Value))
+	}
+	c.driver.record(fmt.Sprintf("exec %s [%s]", strings.TrimSpace(query), strings.Join(values, ",")))
+	if strings.HasPrefix(query, "CREATE TABLE") && strings.Contains(query, "schema_migrations") {
+		c.driver.Lock()
+		c.driver.versions = []int64{}
+		c.driver.Unlock()
+	}
+	if strings.Contains(query, "fail") || strings.Contains(strings.Join(values, ","), "fail") {
+		return nil, errors.New("statement failed")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if strings.Contains(query, "information_schema") {
+		c.driver.record(fmt.Sprintf("query primary key %v", args[0].Value))
+		return &fakeRows{
+			columns: []string{"column_name"},
+			types:   []string{"VARCHAR"},
+			values:  [][]driver.Value{{[]byte("id")}},
+		}, nil
+	}
+	if strings.Contains(query, "pg_advisory_lock") || strings.Contains(query, "GET_LOCK") || strings.Contains(query, "sp_getapplock") {
+		c.driver.record(fmt.Sprintf("query %s [%v]", query, args[0].Value))
+		result := int64(1)
+		if c.driver.lockNotTaken {
+			result = 0
+		}
+		return &fakeRows{
+			columns: []string{"result"},
+			types:   []string{"INT"},
+			values:  [][]driver.Value{{result}},
+		}, nil
+	}
+	c.driver.record(fmt.Sprintf("query %s", query))
+	if strings.Contains(query, "schema_migrations") {
+		c.driver.Lock()
+		defer c.driver.Unlock()
+		if c.driver.versions == nil {
+			return nil, errors.New("table does not exist")
+		}
+		rows := &fakeRows{
+			columns: []string{"version"},
+			types:   []string{"BIGINT"},
+		}
+		if !strings.Contains(query, "WHERE 1=0") {
+			for _, version := range c.driver.versions {
+				rows.values = append(rows.values, []driver.Value{version})
+			}
+		}
+		return rows, nil
+	}
+	if strings.Contains(query, "WHERE 1=0") {
+		return &fakeRows{
+			columns: []string{"id", "title", "price", "active", "data"},
+			types:   []string{"INT", "VARCHAR", "DECIMAL", "TINYINT", "BYTEA"},
+		}, nil
+	}
+	return &fakeRows{
+		columns: []string{"id", "title", "price", "active", "data"},
+		types:   []string{"INT", "VARCHAR", "DECIMAL", "TINYINT", "BYTEA"},
+		values: [][]driver.Value{
+			{[]byte("1"), nil, []byte("1.5"), []byte("1"), []byte{1, 2}},
+			{int64(2), []byte("Title Two"), nil, nil, nil},
+		},
+	}, nil
+}
+
+type fakeTx struct {
+	driver *fakeDriver
+}
+
+func (t *fakeTx) Commit() error {
+	t.driver.record("commit")
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.driver.record("rollback")
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	types   []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.types[index]
+}
+
+func (r *fakeRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return r.columns[index] == "title", true
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newFakeClient(t *testing.T) (*Client, *fakeDriver) {
+	d := &fakeDriver{}
+	name := fmt.Sprintf("sqlcore_fake_%s", t.Name())
+	sql.Register(name, d)
+	db, err := Open(context.Background(), name, "")
+	require.NoError(t, err)
+	return NewClient(db, Postgres), d
+}
+
+func TestClient_Do(t *testing.T) {
+	tests := []struct {
+		name         string
+		request      *types.Request
+		methods      []string
+		wantLog      []string
+		wantData     string
+		wantMetadata map[string]string
+		wantErr      bool
+		wantInvalid  bool
+	}{
+		{
+			name: "query",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "query").
+				SetData([]byte("select * from post")),
+			wantLog:  []string{"query select * from post"},
+			wantData: `[{"active":true,"data":"AQI=","id":1,"price":1.5},{"id":2,"title":"Title Two"}]`,
+		},
+		{
+			name: "query json lines with columns and nulls",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "query").
+				SetMetadataKeyValue("format", "json_lines").
+				SetMetadataKeyValue("include_columns", "true").
+				SetMetadataKeyValue("include_nulls", "true").
+				SetData([]byte("select * from post")),
+			wantLog:  []string{"query select * from post"},
+			wantData: "{\"id\":1,\"title\":null,\"price\":1.5,\"active\":true,\"data\":\"AQI=\"}\n{\"id\":2,\"title\":\"Title Two\",\"price\":null,\"active\":null,\"data\":null}",
+			wantMetadata: map[string]string{
+				"rows":    "2",
+				"columns": `[{"name":"id","type":"INT","nullable":false},{"name":"title","type":"VARCHAR","nullable":true},{"name":"price","type":"DECIMAL","nullable":false},{"name":"active","type":"TINYINT","nullable":false},{"name":"data","type":"BYTEA","nullable":false}]`,
+			},
+		},
+		{
+			name: "query csv",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "query").
+				SetMetadataKeyValue("format", "csv").
+				SetData([]byte("select * from post")),
+			wantLog:  []string{"query select * from post"},
+			wantData: "id,title,price,active,data\n1,,1.5,true,AQI=\n2,Title Two,,,\n",
+		},
+		{
+			name: "query with limit",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "query").
+				SetMetadataKeyValue("limit", "1").
+				SetData([]byte("select * from post")),
+			wantLog:  []string{"query select * from post"},
+			wantData: `[{"id":1,"price":1.5,"active":true,"data":"AQI="}]`,
+			wantMetadata: map[string]string{
+				"rows":        "1",
+				"next_cursor": newCursor(Statement{Query: "select * from post"}, 1),
+			},
+		},
+		{
+			name: "query with cursor",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "query").
+				SetMetadataKeyValue("limit", "1").
+				SetMetadataKeyValue("cursor", newCursor(Statement{Query: "select * from post"}, 1)).
+				SetData([]byte("select * from post")),
+			wantLog:  []string{"query select * from post"},
+			wantData: `[{"id":2,"title":"Title Two"}]`,
+			wantMetadata: map[string]string{
+				"rows": "1",
+			},
+		},
+		{
+			name: "query with cursor of another statement",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "query").
+				SetMetadataKeyValue("cursor", newCursor(Statement{Query: "select * from comment"}, 1)).
+				SetData([]byte("select * from post")),
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name: "query with invalid format",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "query").
+				SetMetadataKeyValue("format", "xml").
+				SetData([]byte("select * from post")),
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name: "exec with params",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "exec").
+				SetMetadataKeyValue("params", `{"id":1}`).
+				SetData([]byte("delete from post where id = :id")),
+			wantLog: []string{"exec delete from post where id = $1 [1]"},
+		},
+		{
+			name: "transaction",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "transaction").
+				SetMetadataKeyValue("isolation_level", "read_uncommitted").
+				SetData([]byte("delete from post;insert into post values (1)")),
+			wantLog: []string{"begin Read Uncommitted", "exec delete from post []", "exec insert into post values (1) []", "commit"},
+		},
+		{
+			name: "transaction with connector isolation level value",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "transaction").
+				SetMetadataKeyValue("isolation_level", "Serializable").
+				SetData([]byte("delete from post")),
+			wantLog: []string{"begin Serializable", "exec delete from post []", "commit"},
+		},
+		{
+			name: "transaction rollback",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "transaction").
+				SetData([]byte("delete from post;fail")),
+			wantLog: []string{"begin Default", "exec delete from post []", "exec fail []", "rollback"},
+			wantErr: true,
+		},
+		{
+			name: "unsupported method",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "transaction").
+				SetData([]byte("delete from post")),
+			methods:     []string{"query", "exec"},
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name: "invalid isolation level",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "transaction").
+				SetMetadataKeyValue("isolation_level", "bad_level").
+				SetData([]byte("delete from post")),
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name: "invalid params",
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "query").
+				SetMetadataKeyValue("params", `{"id":1`).
+				SetData([]byte("select * from post where id = :id")),
+			wantErr:     true,
+			wantInvalid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, d := newFakeClient(t)
+			defer func() {
+				_ = c.Close()
+			}()
+			if tt.methods != nil {
+				c.SetMethods(tt.methods...)
+			}
+			resp, err := c.Do(context.Background(), tt.request)
+			require.Equal(t, tt.wantLog, d.entries())
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, tt.wantInvalid, types.ErrorClassOf(err) == types.ErrorClassInvalidRequest)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "ok", resp.Metadata["result"])
+			for key, value := range tt.wantMetadata {
+				require.Equal(t, value, resp.Metadata[key], key)
+			}
+			_, hasCursor := resp.Metadata["next_cursor"]
+			require.Equal(t, tt.wantMetadata["next_cursor"] != "", hasCursor)
+			if tt.wantData == "" {
+				require.Nil(t, resp.Data)
+				return
+			}
+			if tt.request.Metadata["format"] == "" {
+				require.JSONEq(t, tt.wantData, string(resp.Data))
+				return
+			}
+			require.Equal(t, tt.wantData, string(resp.Data))
+		})
+	}
+}
+
+func TestClient_SetTxFunc(t *testing.T) {
+	c, d := newFakeClient(t)
+	defer func() {
+		_ = c.Close()
+	}()
+	attempts := 0
+	c.SetTxFunc(func(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+		attempts++
+		return executeTx(ctx, db, opts, fn)
+	})
+	_, err := c.Do(context.Background(), types.NewRequest().
+		SetMetadataKeyValue("method", "transaction").
+		SetData([]byte("delete from post")))
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts)
+	require.Equal(t, []string{"begin Default", "exec delete from post []", "commit"}, d.entries())
+}
+
+func TestClient_Insert(t *testing.T) {
+	const (
+		columnsQuery = `query SELECT * FROM "post" WHERE 1=0`
+		primaryKey   = "query primary key post"
+	)
+	tests := []struct {
+		name         string
+		dialect      *Dialect
+		request      *types.Request
+		wantLog      []string
+		wantAffected string
+		wantErr      bool
+		wantInvalid  bool
+		// wantFailedRow is the failed row of a partial write without transactions
+		wantFailedRow string
+	}{
+		{
+			name:    "insert object",
+			dialect: Postgres,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "insert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`{"title":"Title One","ID":1}`)),
+			wantLog: []string{
+				columnsQuery,
+				primaryKey,
+				`exec INSERT INTO "post" ("id", "title") VALUES ($1, $2) [1,Title One]`,
+			},
+			wantAffected: "1",
+		},
+		{
+			name:    "insert array in transaction",
+			dialect: Postgres,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "insert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`[{"id":1,"title":"Title One"},{"id":2,"price":{"type":"float","value":"2.5"}}]`)),
+			wantLog: []string{
+				columnsQuery,
+				primaryKey,
+				"begin Default",
+				`exec INSERT INTO "post" ("id", "title") VALUES ($1, $2) [1,Title One]`,
+				`exec INSERT INTO "post" ("id", "price") VALUES ($1, $2) [2,2.5]`,
+				"commit",
+			},
+			wantAffected: "2",
+		},
+		{
+			name:    "bulk insert",
+			dialect: MySQL,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "bulk_insert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`[{"id":1,"title":"Title One"},{"id":2,"title":"Title Two"},{"id":3}]`)),
+			wantLog: []string{
+				"query SELECT * FROM `post` WHERE 1=0",
+				primaryKey,
+				"begin Default",
+				"exec INSERT INTO `post` (`id`, `title`) VALUES (?, ?), (?, ?) [1,Title One,2,Title Two]",
+				"exec INSERT INTO `post` (`id`) VALUES (?) [3]",
+				"commit",
+			},
+			wantAffected: "2",
+		},
+		{
+			name:    "bulk insert without transactions",
+			dialect: Crate,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "bulk_insert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`[{"id":1,"title":"Title One"},{"id":2,"title":"Title Two"},{"id":3}]`)),
+			wantLog: []string{
+				columnsQuery,
+				primaryKey,
+				`exec INSERT INTO "post" ("id", "title") VALUES ($1, $2), ($3, $4) [1,Title One,2,Title Two]`,
+				`exec INSERT INTO "post" ("id") VALUES ($1) [3]`,
+			},
+			wantAffected: "2",
+		},
+		{
+			name:    "insert partial write without transactions",
+			dialect: Crate,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "insert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`[{"id":1,"title":"Title One"},{"id":2,"title":"fail"},{"id":3}]`)),
+			wantLog: []string{
+				columnsQuery,
+				primaryKey,
+				`exec INSERT INTO "post" ("id", "title") VALUES ($1, $2) [1,Title One]`,
+				`exec INSERT INTO "post" ("id", "title") VALUES ($1, $2) [2,fail]`,
+			},
+			wantAffected:  "1",
+			wantErr:       true,
+			wantFailedRow: "1",
+		},
+		{
+			name:    "insert first row failed without transactions",
+			dialect: Crate,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "insert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`[{"id":1,"title":"fail"},{"id":2}]`)),
+			wantLog: []string{
+				columnsQuery,
+				primaryKey,
+				`exec INSERT INTO "post" ("id", "title") VALUES ($1, $2) [1,fail]`,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "upsert on conflict",
+			dialect: Postgres,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "upsert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`{"id":1,"title":"Title One"}`)),
+			wantLog: []string{
+				columnsQuery,
+				primaryKey,
+				`exec INSERT INTO "post" ("id", "title") VALUES ($1, $2) ON CONFLICT ("id") DO UPDATE SET "title" = EXCLUDED."title" [1,Title One]`,
+			},
+			wantAffected: "1",
+		},
+		{
+			name:    "upsert on conflict do nothing",
+			dialect: Postgres,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "upsert").
+				SetMetadataKeyValue("table", "post").
+				SetMetadataKeyValue("key_columns", "id, title").
+				SetData([]byte(`{"id":1,"title":"Title One"}`)),
+			wantLog: []string{
+				columnsQuery,
+				primaryKey,
+				`exec INSERT INTO "post" ("id", "title") VALUES ($1, $2) ON CONFLICT ("id", "title") DO NOTHING [1,Title One]`,
+			},
+			wantAffected: "1",
+		},
+		{
+			name:    "upsert on duplicate key",
+			dialect: MySQL,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "upsert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`{"id":1,"title":"Title One"}`)),
+			wantLog: []string{
+				"query SELECT * FROM `post` WHERE 1=0",
+				primaryKey,
+				"exec INSERT INTO `post` (`id`, `title`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `title` = VALUES(`title`) [1,Title One]",
+			},
+			wantAffected: "1",
+		},
+		{
+			name:    "upsert merge",
+			dialect: SQLServer,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "upsert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`{"id":1,"title":"Title One"}`)),
+			wantLog: []string{
+				"query SELECT * FROM [post] WHERE 1=0",
+				primaryKey,
+				"exec MERGE INTO [post] WITH (HOLDLOCK) AS target USING (SELECT @p1 AS [id], @p2 AS [title]) AS source ON (target.[id] = source.[id]) " +
+					"WHEN MATCHED THEN UPDATE SET target.[title] = source.[title] WHEN NOT MATCHED THEN INSERT ([id], [title]) VALUES (source.[id], source.[title]); [1,Title One]",
+			},
+			wantAffected: "1",
+		},
+		{
+			name:    "upsert not supported",
+			dialect: Redshift,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "upsert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`{"id":1,"title":"Title One"}`)),
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name:    "upsert row without key column",
+			dialect: Postgres,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "upsert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`{"title":"Title One"}`)),
+			wantLog:     []string{columnsQuery, primaryKey},
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name:    "unknown column",
+			dialect: Postgres,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "insert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`{"id":1,"author":"someone"}`)),
+			wantLog:     []string{columnsQuery, primaryKey, columnsQuery, primaryKey},
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name:    "no table",
+			dialect: Postgres,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "insert").
+				SetData([]byte(`{"id":1}`)),
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name:    "invalid rows",
+			dialect: Postgres,
+			request: types.NewRequest().
+				SetMetadataKeyValue("method", "bulk_insert").
+				SetMetadataKeyValue("table", "post").
+				SetData([]byte(`[{"id":1},2]`)),
+			wantErr:     true,
+			wantInvalid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, d := newFakeClient(t)
+			defer func() {
+				_ = c.Close()
+			}()
+			c.dialect = tt.dialect
+			resp, err := c.Do(context.Background(), tt.request)
+			require.Equal(t, tt.wantLog, d.entries())
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, tt.wantInvalid, types.ErrorClassOf(err) == types.ErrorClassInvalidRequest)
+				if tt.wantFailedRow == "" {
+					require.Nil(t, resp)
+					return
+				}
+				require.Equal(t, "partial", resp.Metadata["result"])
+				require.Equal(t, tt.wantAffected, resp.Metadata["rows_affected"])
+				require.Equal(t, tt.wantFailedRow, resp.Metadata["failed_row"])
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "ok", resp.Metadata["result"])
+			require.Equal(t, tt.wantAffected, resp.Metadata["rows_affected"])
+		})
+	}
+}
+
+func TestClient_InsertTableCache(t *testing.T) {
+	c, d := newFakeClient(t)
+	defer func() {
+		_ = c.Close()
+	}()
+	for i := 0; i < 2; i++ {
+		_, err := c.Do(context.Background(), types.NewRequest().
+			SetMetadataKeyValue("method", "insert").
+			SetMetadataKeyValue("table", "public.post").
+			SetData([]byte(`{"id":1}`)))
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{
+		`query SELECT * FROM "public"."post" WHERE 1=0`,
+		"query primary key post",
+		`exec INSERT INTO "public"."post" ("id") VALUES ($1) [1]`,
+		`exec INSERT INTO "public"."post" ("id") VALUES ($1) [1]`,
+	}, d.entries())
+}
+
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		typeName string
+		want     interface{}
+	}{
+		{name: "null", value: nil, typeName: "VARCHAR", want: nil},
+		{name: "driver typed value", value: int64(1), typeName: "INT", want: int64(1)},
+		{name: "bigint", value: []byte("10"), typeName: "BIGINT", want: int64(10)},
+		{name: "bool", value: []byte("true"), typeName: "bool", want: true},
+		{name: "tinyint", value: []byte("5"), typeName: "TINYINT", want: int64(5)},
+		{name: "float", value: []byte("1.5"), typeName: "FLOAT", want: float32(1.5)},
+		{name: "double", value: []byte("1.25"), typeName: "DOUBLE", want: 1.25},
+		{name: "decimal", value: []byte("12345678901234567890.123456789"), typeName: "NUMERIC", want: json.Number("12345678901234567890.123456789")},
+		{name: "money", value: []byte("$1.00"), typeName: "MONEY", want: "$1.00"},
+		{name: "binary", value: []byte{0, 1}, typeName: "VARBINARY", want: []byte{0, 1}},
+		{name: "time", value: time.Date(2021, 1, 2, 10, 0, 0, 5, time.UTC), typeName: "TIMESTAMPTZ", want: "2021-01-02T10:00:00.000000005Z"},
+		{name: "date", value: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), typeName: "DATE", want: "2021-01-02"},
+		{name: "raw datetime", value: []byte("2021-01-02 10:00:00.5"), typeName: "DATETIME", want: "2021-01-02T10:00:00.5Z"},
+		{name: "raw date", value: []byte("2021-01-02"), typeName: "DATE", want: "2021-01-02"},
+		{name: "invalid raw datetime", value: []byte("0000-00-00 00:00:00"), typeName: "DATETIME", want: "0000-00-00 00:00:00"},
+		{name: "text", value: []byte("text"), typeName: "TEXT", want: "text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, convertValue(tt.value, tt.typeName))
+		})
+	}
+}
+
+func TestParsePoolOptions(t *testing.T) {
+	o, err := ParsePoolOptions(types.Metadata{})
+	require.NoError(t, err)
+	require.Equal(t, PoolOptions{MaxIdleConnections: 10, MaxOpenConnections: 100, ConnectionMaxLifetimeSeconds: 3600}, o)
+	_, err = ParsePoolOptions(types.Metadata{"max_open_connections": "0"})
+	require.Error(t, err)
+}
@@ -0,0 +1,476 @@
+package sqlcore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var errUnknownColumn = errors.New("unknown column")
+
+// table is the cached columns and primary key of a database table
+type table struct {
+	name    string
+	quoted  string
+	columns []string
+	// index of the columns by lower case name
+	index map[string]int
+	keys  []string
+}
+
+// tableRow is a row of json values ordered by the table columns order
+type tableRow struct {
+	columns []string
+	values  []interface{}
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// sqlDB is the client database, or a connection of it, which statements are executed on
+type sqlDB interface {
+	execer
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+func (c *Client) Insert(ctx context.Context, meta Metadata, value []byte) (*types.Response, error) {
+	return c.writeRows(ctx, meta, value, func(t *table, rows []tableRow) ([]Statement, []int, error) {
+		var stmts []Statement
+		var firstRows []int
+		for i, row := range rows {
+			stmts = append(stmts, c.insertStatement(t, row.columns, [][]interface{}{row.values}))
+			firstRows = append(firstRows, i)
+		}
+		return stmts, firstRows, nil
+	})
+}
+
+func (c *Client) BulkInsert(ctx context.Context, meta Metadata, value []byte) (*types.Response, error) {
+	return c.writeRows(ctx, meta, value, func(t *table, rows []tableRow) ([]Statement, []int, error) {
+		var stmts []Statement
+		var firstRows []int
+		// consecutive rows with the same columns are inserted by one statement, up to the dialect max params
+		for start := 0; start < len(rows); {
+			columns := rows[start].columns
+			maxRows := c.dialect.maxParams / len(columns)
+			if maxRows < 1 {
+				maxRows = 1
+			}
+			var batch [][]interface{}
+			end := start
+			for end < len(rows) && len(batch) < maxRows && equalColumns(rows[end].columns, columns) {
+				batch = append(batch, rows[end].values)
+				end++
+			}
+			stmts = append(stmts, c.insertStatement(t, columns, batch))
+			firstRows = append(firstRows, start)
+			start = end
+		}
+		return stmts, firstRows, nil
+	})
+}
+
+func (c *Client) Upsert(ctx context.Context, meta Metadata, value []byte) (*types.Response, error) {
+	if c.dialect.upsert == upsertNone {
+		return nil, types.NewInvalidRequestError(fmt.Errorf("upsert is not supported by %s", c.dialect.Name))
+	}
+	return c.writeRows(ctx, meta, value, func(t *table, rows []tableRow) ([]Statement, []int, error) {
+		keys := t.keys
+		if len(meta.KeyColumns) > 0 {
+			keys = nil
+			for _, key := range meta.KeyColumns {
+				i, ok := t.index[strings.ToLower(key)]
+				if !ok {
+					return nil, nil, fmt.Errorf("key column %s not found in table %s", key, t.name)
+				}
+				keys = append(keys, t.columns[i])
+			}
+		}
+		if len(keys) == 0 && c.dialect.upsert != upsertOnDuplicateKey {
+			return nil, nil, fmt.Errorf("no primary key found for table %s, set key_columns metadata", t.name)
+		}
+		var stmts []Statement
+		var firstRows []int
+		for i, row := range rows {
+			for _, key := range keys {
+				if !containsColumn(row.columns, key) {
+					return nil, nil, fmt.Errorf("key column %s not found in row %d", key, i)
+				}
+			}
+			stmts = append(stmts, c.upsertStatement(t, row, keys))
+			firstRows = append(firstRows, i)
+		}
+		return stmts, firstRows, nil
+	})
+}
+
+// writeRows parses the json rows of the table set by the table metadata, builds their statements and executes them
+// in a transaction, when supported by the database. build returns the statements and the index of the first row
+// written by each statement. Without transactions, a failed statement after rows were written returns the rows
+// affected and the first row of the failed statement with the error
+func (c *Client) writeRows(ctx context.Context, meta Metadata, value []byte, build func(t *table, rows []tableRow) ([]Statement, []int, error)) (*types.Response, error) {
+	if meta.Table == "" {
+		return nil, types.NewInvalidRequestError(fmt.Errorf("no table found, set table metadata"))
+	}
+	rows, err := parseRows(value)
+	if err != nil {
+		return nil, types.NewInvalidRequestError(err)
+	}
+	t, err := c.loadTable(ctx, meta.Table, false)
+	if err != nil {
+		return nil, err
+	}
+	tableRows, err := t.orderRows(rows)
+	if errors.Is(err, errUnknownColumn) {
+		// the table may have been altered since its columns were cached
+		t, err = c.loadTable(ctx, meta.Table, true)
+		if err != nil {
+			return nil, err
+		}
+		tableRows, err = t.orderRows(rows)
+	}
+	if err != nil {
+		return nil, types.NewInvalidRequestError(err)
+	}
+	stmts, firstRows, err := build(t, tableRows)
+	if err != nil {
+		return nil, types.NewInvalidRequestError(err)
+	}
+	affected, err := c.execStatements(ctx, c.db, meta.IsolationLevel, stmts)
+	if err != nil {
+		var stmtErr *statementError
+		if c.dialect.noTransactions && errors.As(err, &stmtErr) && stmtErr.index > 0 {
+			failedRow := firstRows[stmtErr.index]
+			return types.NewResponse().
+					SetMetadataKeyValue("result", "partial").
+					SetMetadataKeyValue("rows_affected", strconv.FormatInt(affected, 10)).
+					SetMetadataKeyValue("failed_row", strconv.Itoa(failedRow)),
+				fmt.Errorf("partial write, %d rows affected before row %d failed, %w", affected, failedRow, err)
+		}
+		return nil, err
+	}
+	return types.NewResponse().
+			SetMetadataKeyValue("result", "ok").
+			SetMetadataKeyValue("rows_affected", strconv.FormatInt(affected, 10)),
+		nil
+}
+
+// statementError is the error of a failed statement of execStatements
+type statementError struct {
+	index int
+	err   error
+}
+
+func (e *statementError) Error() string {
+	return fmt.Sprintf("error on statement %d, %s", e.index, e.err.Error())
+}
+
+func (e *statementError) Unwrap() error {
+	return e.err
+}
+
+// execStatements executes the statements, more than one statement is executed in a transaction and the rows
+// affected of all the statements are returned. Without transactions, the rows affected by the statements executed
+// before a failed statement are returned with its error
+func (c *Client) execStatements(ctx context.Context, db sqlDB, isolationLevel sql.IsolationLevel, stmts []Statement) (int64, error) {
+	var affected int64
+	exec := func(e execer) error {
+		affected = 0
+		for i, stmt := range stmts {
+			result, err := e.ExecContext(ctx, stmt.Query, stmt.Args...)
+			if err != nil {
+				return &statementError{index: i, err: err}
+			}
+			if rows, err := result.RowsAffected(); err == nil {
+				affected += rows
+			}
+		}
+		return nil
+	}
+	if len(stmts) == 1 || c.dialect.noTransactions {
+		err := exec(db)
+		return affected, err
+	}
+	err := c.executeDBTx(ctx, db, &sql.TxOptions{
+		Isolation: isolationLevel,
+		ReadOnly:  false,
+	}, func(tx *sql.Tx) error {
+		return exec(tx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// loadTable returns the cached columns and primary key of a table, the table is introspected when not cached or when
+// reload is set
+func (c *Client) loadTable(ctx context.Context, name string, reload bool) (*table, error) {
+	c.mu.Lock()
+	t, ok := c.tables[name]
+	c.mu.Unlock()
+	if ok && !reload {
+		return t, nil
+	}
+	parts, quoted, err := c.quoteTable(name)
+	if err != nil {
+		return nil, types.NewInvalidRequestError(err)
+	}
+	t = &table{
+		name:   name,
+		quoted: quoted,
+		index:  map[string]int{},
+	}
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s WHERE 1=0", t.quoted))
+	if err != nil {
+		return nil, fmt.Errorf("error reading table %s columns, %w", name, err)
+	}
+	t.columns, err = rows.Columns()
+	_ = rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading table %s columns, %w", name, err)
+	}
+	for i, column := range t.columns {
+		t.index[strings.ToLower(column)] = i
+	}
+	schema, tableName := "", parts[0]
+	if len(parts) == 2 {
+		schema, tableName = parts[0], parts[1]
+	}
+	// the primary key is only required by upserts without key_columns metadata, so introspection errors are ignored
+	t.keys, _ = c.primaryKey(ctx, schema, tableName)
+	c.mu.Lock()
+	c.tables[name] = t
+	c.mu.Unlock()
+	return t, nil
+}
+
+// quoteTable returns the parts and the quoted name of a table name, which is optionally prefixed by its schema
+func (c *Client) quoteTable(name string) ([]string, string, error) {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return nil, "", fmt.Errorf("invalid table name %s", name)
+	}
+	var quoted []string
+	for _, part := range parts {
+		if part == "" {
+			return nil, "", fmt.Errorf("invalid table name %s", name)
+		}
+		quoted = append(quoted, c.dialect.quote(part))
+	}
+	return parts, strings.Join(quoted, "."), nil
+}
+
+func (c *Client) primaryKey(ctx context.Context, schema, tableName string) ([]string, error) {
+	args := []interface{}{tableName}
+	schemaExpr := c.dialect.currentSchema
+	if schema != "" {
+		args = append(args, schema)
+		schemaExpr = c.dialect.placeholder(2)
+	}
+	query := fmt.Sprintf(`SELECT kcu.column_name FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu ON tc.constraint_name = kcu.constraint_name AND tc.table_schema = kcu.table_schema AND tc.table_name = kcu.table_name
+WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_name = %s AND tc.table_schema = %s
+ORDER BY kcu.ordinal_position`, c.dialect.placeholder(1), schemaExpr)
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// parseRows parses the json object or json array of objects of insert requests
+func parseRows(data []byte) ([]map[string]interface{}, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("no rows found")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid rows, %w", err)
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{v}, nil
+	case []interface{}:
+		var rows []map[string]interface{}
+		for i, item := range v {
+			row, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid row %d, rows must be json objects", i)
+			}
+			rows = append(rows, row)
+		}
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("no rows found")
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("invalid rows, data must be a json object or array of objects")
+	}
+}
+
+// orderRows converts the rows values to driver values, ordered by the table columns
+func (t *table) orderRows(rows []map[string]interface{}) ([]tableRow, error) {
+	var result []tableRow
+	for i, row := range rows {
+		if len(row) == 0 {
+			return nil, fmt.Errorf("invalid row %d, no columns found", i)
+		}
+		var positions []int
+		for key := range row {
+			position, ok := t.index[strings.ToLower(key)]
+			if !ok {
+				return nil, fmt.Errorf("%w %s in row %d of table %s", errUnknownColumn, key, i, t.name)
+			}
+			positions = append(positions, position)
+		}
+		sort.Ints(positions)
+		values := map[int]interface{}{}
+		for key, v := range row {
+			value, err := convertParam(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid column %s value in row %d, %w", key, i, err)
+			}
+			values[t.index[strings.ToLower(key)]] = value
+		}
+		tr := tableRow{}
+		for j, position := range positions {
+			if j > 0 && position == positions[j-1] {
+				return nil, fmt.Errorf("invalid row %d, duplicate column %s", i, t.columns[position])
+			}
+			tr.columns = append(tr.columns, t.columns[position])
+			tr.values = append(tr.values, values[position])
+		}
+		result = append(result, tr)
+	}
+	return result, nil
+}
+
+func (c *Client) quoteColumns(columns []string, prefix string) []string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = prefix + c.dialect.quote(column)
+	}
+	return quoted
+}
+
+// insertStatement returns a multi rows insert statement of rows with the same columns
+func (c *Client) insertStatement(t *table, columns []string, rows [][]interface{}) Statement {
+	var sb strings.Builder
+	var args []interface{}
+	fmt.Fprintf(&sb, "INSERT INTO %s (%s) VALUES ", t.quoted, strings.Join(c.quoteColumns(columns, ""), ", "))
+	for i, values := range rows {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(")
+		for j, value := range values {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			args = append(args, value)
+			sb.WriteString(c.dialect.placeholder(len(args)))
+		}
+		sb.WriteString(")")
+	}
+	return Statement{Query: sb.String(), Args: args}
+}
+
+// upsertStatement returns the dialect insert or update statement of a row, the row is matched by the key columns
+func (c *Client) upsertStatement(t *table, row tableRow, keys []string) Statement {
+	var updates []string
+	for _, column := range row.columns {
+		if !containsColumn(keys, column) {
+			updates = append(updates, column)
+		}
+	}
+	if c.dialect.upsert == upsertMerge {
+		return c.mergeStatement(t, row, keys, updates)
+	}
+	stmt := c.insertStatement(t, row.columns, [][]interface{}{row.values})
+	var set []string
+	switch c.dialect.upsert {
+	case upsertOnConflict:
+		for _, column := range updates {
+			set = append(set, fmt.Sprintf("%s = EXCLUDED.%s", c.dialect.quote(column), c.dialect.quote(column)))
+		}
+		if len(set) == 0 {
+			stmt.Query += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(c.quoteColumns(keys, ""), ", "))
+		} else {
+			stmt.Query += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(c.quoteColumns(keys, ""), ", "), strings.Join(set, ", "))
+		}
+	case upsertOnDuplicateKey:
+		if len(updates) == 0 {
+			updates = row.columns[:1]
+		}
+		for _, column := range updates {
+			set = append(set, fmt.Sprintf("%s = VALUES(%s)", c.dialect.quote(column), c.dialect.quote(column)))
+		}
+		stmt.Query += fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s", strings.Join(set, ", "))
+	}
+	return stmt
+}
+
+func (c *Client) mergeStatement(t *table, row tableRow, keys, updates []string) Statement {
+	var source, on, set []string
+	for i, column := range row.columns {
+		source = append(source, fmt.Sprintf("%s AS %s", c.dialect.placeholder(i+1), c.dialect.quote(column)))
+	}
+	for _, key := range keys {
+		on = append(on, fmt.Sprintf("target.%s = source.%s", c.dialect.quote(key), c.dialect.quote(key)))
+	}
+	for _, column := range updates {
+		set = append(set, fmt.Sprintf("target.%s = source.%s", c.dialect.quote(column), c.dialect.quote(column)))
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "MERGE INTO %s WITH (HOLDLOCK) AS target USING (SELECT %s) AS source ON (%s)",
+		t.quoted, strings.Join(source, ", "), strings.Join(on, " AND "))
+	if len(set) > 0 {
+		fmt.Fprintf(&sb, " WHEN MATCHED THEN UPDATE SET %s", strings.Join(set, ", "))
+	}
+	fmt.Fprintf(&sb, " WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s);",
+		strings.Join(c.quoteColumns(row.columns, ""), ", "), strings.Join(c.quoteColumns(row.columns, "source."), ", "))
+	return Statement{Query: sb.String(), Args: row.values}
+}
+
+func equalColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsColumn(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
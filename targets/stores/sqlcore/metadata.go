@@ -0,0 +1,104 @@
+package sqlcore
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"math"
+	"strings"
+)
+
+var methodsMap = map[string]string{
+	"query":       "query",
+	"exec":        "exec",
+	"transaction": "transaction",
+	"insert":      "insert",
+	"upsert":      "upsert",
+	"bulk_insert": "bulk_insert",
+	"migrate":     "migrate",
+}
+
+// isolation levels are set as read_committed or as the connector option values, such as ReadCommitted
+var isolationLevelsMap = map[string]string{
+	"read_uncommitted": "ReadUncommitted",
+	"read_committed":   "ReadCommitted",
+	"repeatable_read":  "RepeatableRead",
+	"serializable":     "Serializable",
+	"":                 "Default",
+	"ReadUncommitted":  "ReadUncommitted",
+	"ReadCommitted":    "ReadCommitted",
+	"RepeatableRead":   "RepeatableRead",
+	"Serializable":     "Serializable",
+	"Default":          "Default",
+}
+
+var formatsMap = map[string]string{
+	"json":       FormatJSON,
+	"json_lines": FormatJSONLines,
+	"csv":        FormatCSV,
+	"":           FormatJSON,
+}
+
+// Metadata is the request metadata of the sql targets
+type Metadata struct {
+	Method         string
+	IsolationLevel sql.IsolationLevel
+	Params         string
+	// query results options
+	Format         string
+	IncludeColumns bool
+	IncludeNulls   bool
+	Limit          int
+	Cursor         string
+	// insert options
+	Table      string
+	KeyColumns []string
+}
+
+func parseMetadata(meta types.Metadata, methods map[string]string) (Metadata, error) {
+	m := Metadata{}
+	var err error
+	m.Method, err = meta.ParseStringMap("method", methods)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("error parsing method, %w", err)
+	}
+	isolationLevel, err := meta.ParseStringMap("isolation_level", isolationLevelsMap)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("error parsing isolation_level, %w", err)
+	}
+	m.IsolationLevel = convertToSqlIsolationLevel(isolationLevel)
+	m.Params = meta.ParseString("params", "")
+	m.Format, err = meta.ParseStringMap("format", formatsMap)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("error parsing format, %w", err)
+	}
+	m.IncludeColumns = meta.ParseBool("include_columns", false)
+	m.IncludeNulls = meta.ParseBool("include_nulls", false)
+	m.Limit, err = meta.ParseIntWithRange("limit", 0, 0, math.MaxInt32)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("error parsing limit, %w", err)
+	}
+	m.Cursor = meta.ParseString("cursor", "")
+	m.Table = meta.ParseString("table", "")
+	for _, column := range strings.Split(meta.ParseString("key_columns", ""), ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			m.KeyColumns = append(m.KeyColumns, column)
+		}
+	}
+	return m, nil
+}
+
+func convertToSqlIsolationLevel(value string) sql.IsolationLevel {
+	switch value {
+	case "ReadUncommitted":
+		return sql.LevelReadUncommitted
+	case "ReadCommitted":
+		return sql.LevelReadCommitted
+	case "RepeatableRead":
+		return sql.LevelRepeatableRead
+	case "Serializable":
+		return sql.LevelSerializable
+	default:
+		return sql.LevelDefault
+	}
+}
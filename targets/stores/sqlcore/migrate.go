@@ -0,0 +1,324 @@
+package sqlcore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"hash/fnv"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migration script files are named <version>_<name>.sql, such as 0001_create_post.sql
+var migrationFileRegex = regexp.MustCompile(`^(\d+)_(.+)\.sql$`)
+
+// Migration is a versioned schema migration script
+type Migration struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	Script  string `json:"script"`
+}
+
+// LoadMigrations loads the migration script files of a directory, ordered by version
+func LoadMigrations(dir string) ([]Migration, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations directory %s, %w", dir, err)
+	}
+	var migrations []Migration
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".sql" {
+			continue
+		}
+		match := migrationFileRegex.FindStringSubmatch(file.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s, file names must be <version>_<name>.sql", file.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s, %w", file.Name(), err)
+		}
+		script, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration file %s, %w", file.Name(), err)
+		}
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    match[2],
+			Script:  string(script),
+		})
+	}
+	return sortMigrations(migrations)
+}
+
+// parseMigrations parses the json array of migrations of migrate requests
+func parseMigrations(data []byte) ([]Migration, error) {
+	var migrations []Migration
+	if err := json.Unmarshal(data, &migrations); err != nil {
+		return nil, fmt.Errorf("invalid migrations, data must be a json array of migrations, %w", err)
+	}
+	for _, m := range migrations {
+		if strings.TrimSpace(m.Script) == "" {
+			return nil, fmt.Errorf("no script found for migration %d", m.Version)
+		}
+	}
+	return sortMigrations(migrations)
+}
+
+func sortMigrations(migrations []Migration) ([]Migration, error) {
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, m := range migrations {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("invalid migration version %d, versions must be positive", m.Version)
+		}
+		if i > 0 && m.Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+	}
+	return migrations, nil
+}
+
+// InitMigrations loads the migrations directory of the options, and applies the pending migrations when the options
+// are set to migrate on init
+func (c *Client) InitMigrations(ctx context.Context, opts MigrationOptions) error {
+	c.migrationsTable = opts.Table
+	if opts.Dir == "" {
+		return nil
+	}
+	migrations, err := LoadMigrations(opts.Dir)
+	if err != nil {
+		return err
+	}
+	c.migrations = migrations
+	if !opts.OnInit {
+		return nil
+	}
+	_, _, err = c.migrate(ctx, sql.LevelDefault, migrations)
+	return err
+}
+
+// Migrate applies the pending migrations of the request data, or of the migrations directory when data is empty, and
+// returns the resulting version
+func (c *Client) Migrate(ctx context.Context, meta Metadata, value []byte) (*types.Response, error) {
+	migrations := c.migrations
+	if len(bytes.TrimSpace(value)) > 0 {
+		var err error
+		migrations, err = parseMigrations(value)
+		if err != nil {
+			return nil, types.NewInvalidRequestError(err)
+		}
+	}
+	if len(migrations) == 0 {
+		return nil, types.NewInvalidRequestError(fmt.Errorf("no migrations found, set migrations in data or set migrations_dir property"))
+	}
+	version, applied, err := c.migrate(ctx, meta.IsolationLevel, migrations)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewResponse().
+			SetMetadataKeyValue("result", "ok").
+			SetMetadataKeyValue("version", strconv.FormatInt(version, 10)).
+			SetMetadataKeyValue("applied", strconv.Itoa(applied)),
+		nil
+}
+
+// migrate applies the migrations which are not recorded in the migrations table, in versions order. Each migration is
+// applied and recorded in its own transaction, when supported by the database. The migrations table is read and the
+// migrations are applied while holding the dialect migrations lock, when supported by the database
+func (c *Client) migrate(ctx context.Context, isolationLevel sql.IsolationLevel, migrations []Migration) (int64, int, error) {
+	_, table, err := c.quoteTable(c.migrationsTable)
+	if err != nil {
+		return 0, 0, err
+	}
+	db, unlock, err := c.lockMigrations(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer unlock()
+	if err := c.createMigrationsTable(ctx, db, table); err != nil {
+		return 0, 0, err
+	}
+	applied, version, err := c.appliedMigrations(ctx, db, table)
+	if err != nil {
+		return 0, 0, err
+	}
+	var pending []Migration
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if m.Version < version {
+			return 0, 0, types.NewInvalidRequestError(fmt.Errorf("migration %d is older than the current version %d", m.Version, version))
+		}
+		pending = append(pending, m)
+	}
+	for i, m := range pending {
+		stmts := []Statement{}
+		for _, query := range splitStatements(m.Script, c.dialect) {
+			stmts = append(stmts, Statement{Query: query})
+		}
+		stmts = append(stmts, Statement{
+			Query: fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (%s, %s, %s)", table,
+				c.dialect.placeholder(1), c.dialect.placeholder(2), c.dialect.placeholder(3)),
+			Args: []interface{}{m.Version, m.Name, time.Now().UTC()},
+		})
+		if _, err := c.execStatements(ctx, db, isolationLevel, stmts); err != nil {
+			return version, i, fmt.Errorf("error applying migration %d %s, %w", m.Version, m.Name, err)
+		}
+		version = m.Version
+	}
+	return version, len(pending), nil
+}
+
+// lockMigrations takes the dialect migrations lock on a connection of the database, so replicas sharing the database
+// apply migrations one at a time. Migrations are applied on the returned connection, which is released with the lock
+// by unlock. The database is returned when the dialect has no migrations lock
+func (c *Client) lockMigrations(ctx context.Context) (sqlDB, func(), error) {
+	lock := c.dialect.migrationsLock
+	if lock == nil {
+		return c.db, func() {}, nil
+	}
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error locking migrations table %s, %w", c.migrationsTable, err)
+	}
+	key := migrationsLockKey(c.migrationsTable, lock.numericKey)
+	var result sql.NullInt64
+	err = conn.QueryRowContext(ctx, fmt.Sprintf(lock.lock, c.dialect.placeholder(1)), key).Scan(&result)
+	if err == nil && result.Int64 != 1 {
+		err = fmt.Errorf("lock result %d", result.Int64)
+	}
+	if err != nil {
+		discardConn(conn)
+		return nil, nil, fmt.Errorf("error locking migrations table %s, %w", c.migrationsTable, err)
+	}
+	unlock := func() {
+		// the lock is released with a background context, so it is released after the request context is canceled
+		_, err := conn.ExecContext(context.Background(), fmt.Sprintf(lock.unlock, c.dialect.placeholder(1)), key)
+		if err != nil {
+			discardConn(conn)
+			return
+		}
+		_ = conn.Close()
+	}
+	return conn, unlock, nil
+}
+
+// discardConn closes a connection instead of returning it to the pool, so the session locks it may hold are released
+func discardConn(conn *sql.Conn) {
+	_ = conn.Raw(func(driverConn interface{}) error {
+		return driver.ErrBadConn
+	})
+	_ = conn.Close()
+}
+
+// migrationsLockKey returns the lock key of a migrations table, a bigint or a name
+func migrationsLockKey(table string, numeric bool) interface{} {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(table))
+	if numeric {
+		return int64(h.Sum64())
+	}
+	return fmt.Sprintf("kubemq_migrations_%x", h.Sum64())
+}
+
+// createMigrationsTable creates the migrations table when it does not exist
+func (c *Client) createMigrationsTable(ctx context.Context, db sqlDB, table string) error {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s WHERE 1=0", table))
+	if err == nil {
+		return rows.Close()
+	}
+	_, err = db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at %s NOT NULL)",
+		table, c.dialect.timestampType))
+	if err != nil {
+		return fmt.Errorf("error creating migrations table %s, %w", c.migrationsTable, err)
+	}
+	return nil
+}
+
+// appliedMigrations returns the applied versions of the migrations table and the current version
+func (c *Client) appliedMigrations(ctx context.Context, db sqlDB, table string) (map[int64]bool, int64, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s", table))
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading migrations table %s, %w", c.migrationsTable, err)
+	}
+	defer rows.Close()
+	applied := map[int64]bool{}
+	var current int64
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, 0, fmt.Errorf("error reading migrations table %s, %w", c.migrationsTable, err)
+		}
+		applied[version] = true
+		if version > current {
+			current = version
+		}
+	}
+	return applied, current, rows.Err()
+}
+
+// splitStatements splits a script at the ';' separators which are outside of quoted strings, identifiers, comments and
+// dollar quoted strings. Statements with only comments are dropped
+func splitStatements(script string, dialect *Dialect) []string {
+	var stmts []string
+	start := 0
+	hasCode := false
+	for i := 0; i < len(script); {
+		ch := script[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			i = skipQuoted(script, i, ch, dialect.backslashEscapes && ch != '`')
+			hasCode = true
+		case ch == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			i += end
+		case ch == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 4
+			}
+		case ch == '$' && dialect.dollarQuotes && dollarTag(script[i:]) != "":
+			tag := dollarTag(script[i:])
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 2*len(tag)
+			}
+			hasCode = true
+		case ch == ';':
+			if hasCode {
+				stmts = append(stmts, strings.TrimSpace(script[start:i]))
+			}
+			i++
+			start = i
+			hasCode = false
+		default:
+			if ch != ' ' && ch != '\t' && ch != '\n' && ch != '\r' {
+				hasCode = true
+			}
+			i++
+		}
+	}
+	if hasCode {
+		stmts = append(stmts, strings.TrimSpace(script[start:]))
+	}
+	return stmts
+}
@@ -0,0 +1,308 @@
+package sqlcore
+
+import (
+	"context"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		dialect *Dialect
+		want    []string
+	}{
+		{
+			name:    "statements",
+			script:  "CREATE TABLE post (id INT);\nINSERT INTO post VALUES (1);\n",
+			dialect: Postgres,
+			want:    []string{"CREATE TABLE post (id INT)", "INSERT INTO post VALUES (1)"},
+		},
+		{
+			name:    "quoted separators",
+			script:  `INSERT INTO post VALUES ('a;b', "c;d"); SELECT 1`,
+			dialect: Postgres,
+			want:    []string{`INSERT INTO post VALUES ('a;b', "c;d")`, "SELECT 1"},
+		},
+		{
+			name:    "comments",
+			script:  "-- create post;\nCREATE TABLE post (id INT); /* done; */\n-- end;",
+			dialect: Postgres,
+			want:    []string{"-- create post;\nCREATE TABLE post (id INT)"},
+		},
+		{
+			name:    "dollar quoted function",
+			script:  "CREATE FUNCTION one() RETURNS INT AS $body$ BEGIN RETURN 1; END; $body$ LANGUAGE plpgsql; SELECT $$a;b$$",
+			dialect: Postgres,
+			want:    []string{"CREATE FUNCTION one() RETURNS INT AS $body$ BEGIN RETURN 1; END; $body$ LANGUAGE plpgsql", "SELECT $$a;b$$"},
+		},
+		{
+			name:    "mysql backslash escapes",
+			script:  `INSERT INTO post VALUES ('a\';b'); SELECT 1;`,
+			dialect: MySQL,
+			want:    []string{`INSERT INTO post VALUES ('a\';b')`, "SELECT 1"},
+		},
+		{
+			name:    "empty script",
+			script:  " ; -- nothing\n",
+			dialect: MySQL,
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, splitStatements(tt.script, tt.dialect))
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    []Migration
+		wantErr bool
+	}{
+		{
+			name: "ordered by version",
+			files: map[string]string{
+				"0002_add_index.sql":   "CREATE INDEX idx ON post (title)",
+				"0001_create_post.sql": "CREATE TABLE post (id INT)",
+				"README.md":            "migrations",
+			},
+			want: []Migration{
+				{Version: 1, Name: "create_post", Script: "CREATE TABLE post (id INT)"},
+				{Version: 2, Name: "add_index", Script: "CREATE INDEX idx ON post (title)"},
+			},
+		},
+		{
+			name: "invalid file name",
+			files: map[string]string{
+				"create_post.sql": "CREATE TABLE post (id INT)",
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate version",
+			files: map[string]string{
+				"1_create_post.sql":  "CREATE TABLE post (id INT)",
+				"01_create_post.sql": "CREATE TABLE post (id INT)",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "migrations")
+			require.NoError(t, err)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			for name, script := range tt.files {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(script), 0600))
+			}
+			got, err := LoadMigrations(dir)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_Migrate(t *testing.T) {
+	const (
+		probe  = `query SELECT version FROM "schema_migrations" WHERE 1=0`
+		create = `exec CREATE TABLE "schema_migrations" (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL) []`
+		list   = `query SELECT version FROM "schema_migrations"`
+		record = `exec INSERT INTO "schema_migrations" (version, name, applied_at) VALUES ($1, $2, $3)`
+	)
+	var (
+		lock        = fmt.Sprintf("query SELECT 1 FROM pg_advisory_lock($1) [%v]", migrationsLockKey("schema_migrations", true))
+		unlock      = fmt.Sprintf("exec SELECT pg_advisory_unlock($1) [%v]", migrationsLockKey("schema_migrations", true))
+		mysqlLock   = fmt.Sprintf("query SELECT GET_LOCK(?, -1) [%v]", migrationsLockKey("schema_migrations", false))
+		mysqlUnlock = fmt.Sprintf("exec SELECT RELEASE_LOCK(?) [%v]", migrationsLockKey("schema_migrations", false))
+	)
+	migrations := `[{"version":2,"name":"add_index","script":"CREATE INDEX idx ON post (title)"},{"version":1,"name":"create_post","script":"CREATE TABLE post (id INT); -- done"}]`
+	tests := []struct {
+		name        string
+		dialect     *Dialect
+		versions    []int64
+		data        string
+		wantLog     []string
+		wantVersion string
+		wantApplied string
+		wantErr     bool
+		wantInvalid bool
+		// lockNotTaken fails the migrations lock
+		lockNotTaken bool
+	}{
+		{
+			name:    "create table and apply",
+			dialect: Postgres,
+			data:    migrations,
+			wantLog: []string{
+				lock, probe, create, list,
+				"begin Default", "exec CREATE TABLE post (id INT) []", record + " [1,create_post,time]", "commit",
+				"begin Default", "exec CREATE INDEX idx ON post (title) []", record + " [2,add_index,time]", "commit",
+				unlock,
+			},
+			wantVersion: "2",
+			wantApplied: "2",
+		},
+		{
+			name:     "apply pending",
+			dialect:  Postgres,
+			versions: []int64{1},
+			data:     migrations,
+			wantLog: []string{
+				lock, probe, list,
+				"begin Default", "exec CREATE INDEX idx ON post (title) []", record + " [2,add_index,time]", "commit",
+				unlock,
+			},
+			wantVersion: "2",
+			wantApplied: "1",
+		},
+		{
+			name:        "no pending",
+			dialect:     Postgres,
+			versions:    []int64{1, 2},
+			data:        migrations,
+			wantLog:     []string{lock, probe, list, unlock},
+			wantVersion: "2",
+			wantApplied: "0",
+		},
+		{
+			name:     "mysql lock",
+			dialect:  MySQL,
+			versions: []int64{1, 2},
+			data:     migrations,
+			wantLog: []string{
+				mysqlLock, "query SELECT version FROM `schema_migrations` WHERE 1=0", "query SELECT version FROM `schema_migrations`", mysqlUnlock,
+			},
+			wantVersion: "2",
+			wantApplied: "0",
+		},
+		{
+			name:         "lock not taken",
+			dialect:      Postgres,
+			data:         migrations,
+			lockNotTaken: true,
+			wantLog:      []string{lock},
+			wantErr:      true,
+		},
+		{
+			name:     "no transactions",
+			dialect:  Crate,
+			versions: []int64{1},
+			data:     migrations,
+			wantLog: []string{
+				probe, list,
+				"exec CREATE INDEX idx ON post (title) []", record + " [2,add_index,time]",
+			},
+			wantVersion: "2",
+			wantApplied: "1",
+		},
+		{
+			name:     "failed migration",
+			dialect:  Postgres,
+			versions: []int64{1},
+			data:     `[{"version":2,"name":"fail","script":"CREATE INDEX fail ON post (title)"}]`,
+			wantLog: []string{
+				lock, probe, list,
+				"begin Default", "exec CREATE INDEX fail ON post (title) []", "rollback",
+				unlock,
+			},
+			wantErr: true,
+		},
+		{
+			name:        "older migration",
+			dialect:     Postgres,
+			versions:    []int64{2},
+			data:        migrations,
+			wantLog:     []string{lock, probe, list, unlock},
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name:        "no migrations",
+			dialect:     Postgres,
+			wantErr:     true,
+			wantInvalid: true,
+		},
+		{
+			name:        "invalid migrations",
+			dialect:     Postgres,
+			data:        `{"version":1}`,
+			wantErr:     true,
+			wantInvalid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, d := newFakeClient(t)
+			defer func() {
+				_ = c.Close()
+			}()
+			c.dialect = tt.dialect
+			d.versions = tt.versions
+			d.lockNotTaken = tt.lockNotTaken
+			resp, err := c.Do(context.Background(), types.NewRequest().
+				SetMetadataKeyValue("method", "migrate").
+				SetData([]byte(tt.data)))
+			require.Equal(t, tt.wantLog, d.entries())
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, tt.wantInvalid, types.ErrorClassOf(err) == types.ErrorClassInvalidRequest)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "ok", resp.Metadata["result"])
+			require.Equal(t, tt.wantVersion, resp.Metadata["version"])
+			require.Equal(t, tt.wantApplied, resp.Metadata["applied"])
+		})
+	}
+}
+
+func TestClient_InitMigrations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrations")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "1_create_post.sql"), []byte("CREATE TABLE post (id INT)"), 0600))
+	c, d := newFakeClient(t)
+	defer func() {
+		_ = c.Close()
+	}()
+	d.versions = []int64{}
+	opts, err := ParseMigrationOptions(types.Metadata{
+		"migrations_dir":   dir,
+		"migrations_table": "public.schema_migrations",
+		"migrate_on_init":  "true",
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.InitMigrations(context.Background(), opts))
+	require.Equal(t, []string{
+		fmt.Sprintf("query SELECT 1 FROM pg_advisory_lock($1) [%v]", migrationsLockKey("public.schema_migrations", true)),
+		`query SELECT version FROM "public"."schema_migrations" WHERE 1=0`,
+		`query SELECT version FROM "public"."schema_migrations"`,
+		"begin Default",
+		"exec CREATE TABLE post (id INT) []",
+		`exec INSERT INTO "public"."schema_migrations" (version, name, applied_at) VALUES ($1, $2, $3) [1,create_post,time]`,
+		"commit",
+		fmt.Sprintf("exec SELECT pg_advisory_unlock($1) [%v]", migrationsLockKey("public.schema_migrations", true)),
+	}, d.entries())
+	_, err = ParseMigrationOptions(types.Metadata{
+		"migrate_on_init": "true",
+	})
+	require.Error(t, err)
+}
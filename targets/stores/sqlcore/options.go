@@ -0,0 +1,72 @@
+package sqlcore
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/kubemq-hub/kubemq-targets/types"
+	"math"
+	"time"
+)
+
+const (
+	defaultMaxIdleConnections           = 10
+	defaultMaxOpenConnections           = 100
+	defaultConnectionMaxLifetimeSeconds = 3600
+	defaultMigrationsTable              = "schema_migrations"
+)
+
+// PoolOptions are the connection pool properties of the sql targets
+type PoolOptions struct {
+	// MaxIdleConnections sets the maximum number of connections in the idle connection pool
+	MaxIdleConnections int
+	// MaxOpenConnections sets the maximum number of open connections to the database.
+	MaxOpenConnections int
+	// ConnectionMaxLifetimeSeconds sets the maximum amount of time a connection may be reused.
+	ConnectionMaxLifetimeSeconds int
+}
+
+func ParsePoolOptions(properties types.Metadata) (PoolOptions, error) {
+	o := PoolOptions{}
+	var err error
+	o.MaxIdleConnections, err = properties.ParseIntWithRange("max_idle_connections", defaultMaxIdleConnections, 1, math.MaxInt32)
+	if err != nil {
+		return PoolOptions{}, fmt.Errorf("error parsing max_idle_connections value, %w", err)
+	}
+	o.MaxOpenConnections, err = properties.ParseIntWithRange("max_open_connections", defaultMaxOpenConnections, 1, math.MaxInt32)
+	if err != nil {
+		return PoolOptions{}, fmt.Errorf("error parsing max_open_connections value, %w", err)
+	}
+	o.ConnectionMaxLifetimeSeconds, err = properties.ParseIntWithRange("connection_max_lifetime_seconds", defaultConnectionMaxLifetimeSeconds, 1, math.MaxInt32)
+	if err != nil {
+		return PoolOptions{}, fmt.Errorf("error parsing connection_max_lifetime_seconds value, %w", err)
+	}
+	return o, nil
+}
+
+// Apply sets the connection pool options of db
+func (o PoolOptions) Apply(db *sql.DB) {
+	db.SetMaxOpenConns(o.MaxOpenConnections)
+	db.SetMaxIdleConns(o.MaxIdleConnections)
+	db.SetConnMaxLifetime(time.Duration(o.ConnectionMaxLifetimeSeconds) * time.Second)
+}
+
+// MigrationOptions are the schema migration properties of the sql targets
+type MigrationOptions struct {
+	// Dir is a directory of <version>_<name>.sql migration scripts
+	Dir string
+	// Table records the applied migrations versions
+	Table string
+	// OnInit applies the pending migrations of Dir at the target init
+	OnInit bool
+}
+
+func ParseMigrationOptions(properties types.Metadata) (MigrationOptions, error) {
+	o := MigrationOptions{}
+	o.Dir = properties.ParseString("migrations_dir", "")
+	o.Table = properties.ParseString("migrations_table", defaultMigrationsTable)
+	o.OnInit = properties.ParseBool("migrate_on_init", false)
+	if o.OnInit && o.Dir == "" {
+		return MigrationOptions{}, fmt.Errorf("error parsing migrate_on_init value, migrations_dir is not set")
+	}
+	return o, nil
+}
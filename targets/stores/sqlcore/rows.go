@@ -0,0 +1,348 @@
+package sqlcore
+
+import (
+	"bytes"
+	"database/sql"
+	b64 "encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// query results formats
+const (
+	FormatJSON      = "json"
+	FormatJSONLines = "json_lines"
+	FormatCSV       = "csv"
+)
+
+const dateLayout = "2006-01-02"
+
+// text layouts of date and time columns which drivers return as raw bytes
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	dateLayout,
+}
+
+// Column is the metadata of a query result column
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable *bool  `json:"nullable,omitempty"`
+}
+
+// results is an encoded page of query results
+type results struct {
+	data    []byte
+	columns []Column
+	count   int
+	more    bool
+}
+
+// resultColumns returns the name, database type and nullability, when reported by the driver, of the rows columns
+func resultColumns(rows *sql.Rows) ([]Column, error) {
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]Column, len(colTypes))
+	for i, colType := range colTypes {
+		columns[i] = Column{
+			Name: colType.Name(),
+			Type: colType.DatabaseTypeName(),
+		}
+		if nullable, ok := colType.Nullable(); ok {
+			columns[i].Nullable = &nullable
+		}
+	}
+	return columns, nil
+}
+
+// rowsEncoder encodes result rows, one row at a time, in a results format
+type rowsEncoder interface {
+	writeRow(values []interface{}) error
+	bytes(count int) ([]byte, error)
+}
+
+// encodeRows skips offset rows and encodes up to limit rows, a limit of 0 encodes all rows. The rows are encoded one at
+// a time, without holding the scanned values of the page in memory. The skipped rows are read from the database, so
+// paging is offset paging over the rows order of the query
+func encodeRows(rows *sql.Rows, columns []Column, format string, includeNulls bool, offset, limit int) (*results, error) {
+	encoder, err := newRowsEncoder(format, columns, includeNulls)
+	if err != nil {
+		return nil, err
+	}
+	for skipped := 0; skipped < offset; skipped++ {
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	res := &results{columns: columns}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for rows.Next() {
+		if limit > 0 && res.count == limit {
+			res.more = true
+			break
+		}
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, column := range columns {
+			values[i] = convertValue(values[i], column.Type)
+		}
+		if err := encoder.writeRow(values); err != nil {
+			return nil, err
+		}
+		res.count++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	res.data, err = encoder.bytes(res.count)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func newRowsEncoder(format string, columns []Column, includeNulls bool) (rowsEncoder, error) {
+	switch format {
+	case FormatJSON, "":
+		return &jsonEncoder{columns: columns, nulls: includeNulls}, nil
+	case FormatJSONLines:
+		return &jsonEncoder{columns: columns, lines: true, nulls: includeNulls}, nil
+	case FormatCSV:
+		return newCSVEncoder(columns)
+	default:
+		return nil, fmt.Errorf("invalid results format %s", format)
+	}
+}
+
+// jsonEncoder encodes the rows as a json array of objects, or as json lines of objects, with the keys in columns order.
+// NULL columns are omitted unless nulls is set
+type jsonEncoder struct {
+	columns []Column
+	lines   bool
+	nulls   bool
+	buf     bytes.Buffer
+}
+
+func (e *jsonEncoder) writeRow(values []interface{}) error {
+	if e.lines {
+		if e.buf.Len() > 0 {
+			e.buf.WriteByte('\n')
+		}
+	} else if e.buf.Len() == 0 {
+		e.buf.WriteByte('[')
+	} else {
+		e.buf.WriteByte(',')
+	}
+	e.buf.WriteByte('{')
+	written := 0
+	for i, column := range e.columns {
+		if values[i] == nil && !e.nulls {
+			continue
+		}
+		if written > 0 {
+			e.buf.WriteByte(',')
+		}
+		written++
+		name, err := json.Marshal(column.Name)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return fmt.Errorf("error encoding column %s, %w", column.Name, err)
+		}
+		e.buf.Write(name)
+		e.buf.WriteByte(':')
+		e.buf.Write(value)
+	}
+	e.buf.WriteByte('}')
+	return nil
+}
+
+func (e *jsonEncoder) bytes(count int) ([]byte, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	if !e.lines {
+		e.buf.WriteByte(']')
+	}
+	return e.buf.Bytes(), nil
+}
+
+// csvEncoder encodes the rows as csv records with a header record of the column names
+type csvEncoder struct {
+	buf    bytes.Buffer
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVEncoder(columns []Column) (*csvEncoder, error) {
+	e := &csvEncoder{
+		record: make([]string, len(columns)),
+	}
+	e.writer = csv.NewWriter(&e.buf)
+	for i, column := range columns {
+		e.record[i] = column.Name
+	}
+	if err := e.writer.Write(e.record); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvEncoder) writeRow(values []interface{}) error {
+	for i, value := range values {
+		e.record[i] = csvValue(value)
+	}
+	return e.writer.Write(e.record)
+}
+
+func (e *csvEncoder) bytes(count int) ([]byte, error) {
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// csvValue formats a converted column value as a csv field, NULL values are empty fields
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return b64.StdEncoding.EncodeToString(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// convertValue converts a scanned column value to its results value by the column database type. Integers and floats
+// are numbers, decimals are exact json numbers, times are RFC3339 strings and dates are yyyy-mm-dd strings. Binary
+// columns are kept as bytes, which are encoded as base64 strings, and other raw bytes are converted to strings
+func convertValue(value interface{}, typeName string) interface{} {
+	typeName = strings.ToUpper(typeName)
+	if t, ok := value.(time.Time); ok {
+		return formatTime(t, typeName)
+	}
+	raw, ok := value.([]byte)
+	if !ok {
+		return value
+	}
+	str := string(raw)
+	switch typeName {
+	case "BOOL", "BOOLEAN", "TINYINT":
+		if v, err := strconv.ParseBool(str); err == nil {
+			return v
+		}
+		if v, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return v
+		}
+	case "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "INT2", "INT4", "INT8", "YEAR":
+		if v, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return v
+		}
+	case "FLOAT", "FLOAT4", "REAL":
+		if v, err := strconv.ParseFloat(str, 32); err == nil {
+			return float32(v)
+		}
+	case "DOUBLE", "FLOAT8":
+		if v, err := strconv.ParseFloat(str, 64); err == nil {
+			return v
+		}
+	case "DECIMAL", "NUMERIC", "MONEY", "SMALLMONEY":
+		if isJSONNumber(str) {
+			return json.Number(str)
+		}
+	case "DATE", "DATETIME", "DATETIME2", "SMALLDATETIME", "TIMESTAMP", "TIMESTAMPTZ":
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, str); err == nil {
+				return formatTime(t, typeName)
+			}
+		}
+	case "BINARY", "VARBINARY", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BYTEA", "IMAGE", "UNIQUEIDENTIFIER":
+		return raw
+	}
+	return str
+}
+
+func formatTime(t time.Time, typeName string) string {
+	if typeName == "DATE" {
+		return t.Format(dateLayout)
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func isJSONNumber(str string) bool {
+	var number json.Number
+	return str != "" && json.Unmarshal([]byte(str), &number) == nil
+}
+
+// cursor is the offset of the next page of a query, bound to the query statement and params
+type cursor struct {
+	Offset int    `json:"offset"`
+	Hash   string `json:"hash"`
+}
+
+func statementHash(stmt Statement) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(stmt.Query))
+	for _, arg := range stmt.Args {
+		_, _ = fmt.Fprintf(h, "\x00%T:%v", arg, arg)
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func newCursor(stmt Statement, offset int) string {
+	data, _ := json.Marshal(&cursor{
+		Offset: offset,
+		Hash:   statementHash(stmt),
+	})
+	return b64.RawURLEncoding.EncodeToString(data)
+}
+
+// parseCursor returns the offset of a cursor token, the token must be of the same statement and params
+func parseCursor(token string, stmt Statement) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	data, err := b64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor, %w", err)
+	}
+	c := &cursor{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return 0, fmt.Errorf("invalid cursor, %w", err)
+	}
+	if c.Offset < 0 {
+		return 0, fmt.Errorf("invalid cursor offset")
+	}
+	if c.Hash != statementHash(stmt) {
+		return 0, fmt.Errorf("cursor does not match the query statement")
+	}
+	return c.Offset, nil
+}
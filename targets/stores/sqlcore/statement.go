@@ -0,0 +1,475 @@
+package sqlcore
+
+import (
+	"bytes"
+	b64 "encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// upsert statements styles
+const (
+	upsertNone           = ""
+	upsertOnConflict     = "on_conflict"
+	upsertOnDuplicateKey = "on_duplicate_key"
+	upsertMerge          = "merge"
+)
+
+// Dialect holds the sql syntax differences between database drivers
+type Dialect struct {
+	// Name of the dialect
+	Name string
+	// numbered placeholders, such as $1, can be referenced more than once
+	numbered         bool
+	placeholder      func(n int) string
+	backslashEscapes bool
+	quote            func(name string) string
+	upsert           string
+	// currentSchema is the sql expression of the connection default schema
+	currentSchema string
+	// maxParams is the max number of parameters of a statement
+	maxParams      int
+	noTransactions bool
+	// dollarQuotes of postgres $$ or $tag$ quoted strings
+	dollarQuotes bool
+	// timestampType is the column type of the migrations applied time
+	timestampType string
+	// migrationsLock is the session lock taken while migrations are applied, migrations are not locked when nil
+	migrationsLock *migrationsLock
+}
+
+// migrationsLock holds the statements of a session lock, formatted with the lock key placeholder. The lock statement
+// returns 1 when the lock is taken
+type migrationsLock struct {
+	lock   string
+	unlock string
+	// numericKey locks are keyed by a bigint instead of a name
+	numericKey bool
+}
+
+var (
+	postgresMigrationsLock = &migrationsLock{
+		lock:       "SELECT 1 FROM pg_advisory_lock(%s)",
+		unlock:     "SELECT pg_advisory_unlock(%s)",
+		numericKey: true,
+	}
+	mysqlMigrationsLock = &migrationsLock{
+		lock:   "SELECT GET_LOCK(%s, -1)",
+		unlock: "SELECT RELEASE_LOCK(%s)",
+	}
+	mssqlMigrationsLock = &migrationsLock{
+		lock: "DECLARE @result INT; EXEC @result = sp_getapplock @Resource = %s, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = -1; " +
+			"SELECT CASE WHEN @result >= 0 THEN 1 ELSE @result END",
+		unlock: "EXEC sp_releaseapplock @Resource = %s, @LockOwner = 'Session'",
+	}
+)
+
+var (
+	// Postgres dialect of postgres compatible drivers, with $1, $2 ... placeholders
+	Postgres = &Dialect{
+		Name:           "postgres",
+		numbered:       true,
+		placeholder:    numberedPlaceholder("$"),
+		quote:          quoteWith(`"`, `"`),
+		upsert:         upsertOnConflict,
+		currentSchema:  "current_schema()",
+		maxParams:      65535,
+		dollarQuotes:   true,
+		timestampType:  "TIMESTAMP",
+		migrationsLock: postgresMigrationsLock,
+	}
+	// CockroachDB dialect of the postgres driver connected to cockroachdb, which has no advisory locks
+	CockroachDB = &Dialect{
+		Name:          "cockroachdb",
+		numbered:      true,
+		placeholder:   numberedPlaceholder("$"),
+		quote:         quoteWith(`"`, `"`),
+		upsert:        upsertOnConflict,
+		currentSchema: "current_schema()",
+		maxParams:     65535,
+		dollarQuotes:  true,
+		timestampType: "TIMESTAMP",
+	}
+	// Redshift dialect of the postgres driver connected to redshift, which has no upsert statement
+	Redshift = &Dialect{
+		Name:          "redshift",
+		numbered:      true,
+		placeholder:   numberedPlaceholder("$"),
+		quote:         quoteWith(`"`, `"`),
+		upsert:        upsertNone,
+		currentSchema: "current_schema()",
+		maxParams:     32767,
+		dollarQuotes:  true,
+		timestampType: "TIMESTAMP",
+	}
+	// Crate dialect of the postgres driver connected to crate, which has no transactions
+	Crate = &Dialect{
+		Name:           "crate",
+		numbered:       true,
+		placeholder:    numberedPlaceholder("$"),
+		quote:          quoteWith(`"`, `"`),
+		upsert:         upsertOnConflict,
+		currentSchema:  "current_schema()",
+		maxParams:      65535,
+		noTransactions: true,
+		dollarQuotes:   true,
+		timestampType:  "TIMESTAMP",
+	}
+	// MySQL dialect of mysql compatible drivers, with ? placeholders
+	MySQL = &Dialect{
+		Name: "mysql",
+		placeholder: func(n int) string {
+			return "?"
+		},
+		backslashEscapes: true,
+		quote:            quoteWith("`", "`"),
+		upsert:           upsertOnDuplicateKey,
+		currentSchema:    "DATABASE()",
+		maxParams:        65535,
+		timestampType:    "DATETIME",
+		migrationsLock:   mysqlMigrationsLock,
+	}
+	// MSSQL dialect of the mssql driver, with ? placeholders
+	MSSQL = &Dialect{
+		Name: "mssql",
+		placeholder: func(n int) string {
+			return "?"
+		},
+		quote:          quoteWith("[", "]"),
+		upsert:         upsertMerge,
+		currentSchema:  "SCHEMA_NAME()",
+		maxParams:      2000,
+		timestampType:  "DATETIME2",
+		migrationsLock: mssqlMigrationsLock,
+	}
+	// SQLServer dialect of the sqlserver driver, with @p1, @p2 ... placeholders
+	SQLServer = &Dialect{
+		Name:           "sqlserver",
+		numbered:       true,
+		placeholder:    numberedPlaceholder("@p"),
+		quote:          quoteWith("[", "]"),
+		upsert:         upsertMerge,
+		currentSchema:  "SCHEMA_NAME()",
+		maxParams:      2000,
+		timestampType:  "DATETIME2",
+		migrationsLock: mssqlMigrationsLock,
+	}
+)
+
+func numberedPlaceholder(prefix string) func(n int) string {
+	return func(n int) string {
+		return fmt.Sprintf("%s%d", prefix, n)
+	}
+}
+
+// quoteWith returns an identifier quoting func, the closing quote is escaped by doubling it
+func quoteWith(open, close string) func(name string) string {
+	return func(name string) string {
+		return open + strings.ReplaceAll(name, close, close+close) + close
+	}
+}
+
+// Statement is a sql statement with its parameters
+type Statement struct {
+	Query string
+	Args  []interface{}
+}
+
+type statementRequest struct {
+	Statement string          `json:"statement"`
+	Params    json.RawMessage `json:"params"`
+}
+
+// ParseStatements parses the statements of exec and transaction requests. Data is either sql statements separated by ';',
+// a json statement object {"statement":"...","params":[...]} or a json array of statement objects.
+// params is a json array or object of the parameters of a single sql statement, set by the params metadata key.
+func ParseStatements(data []byte, params string, dialect *Dialect) ([]Statement, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+	switch trimmed[0] {
+	case '{':
+		stmt, err := parseStatementObject(trimmed, params, dialect)
+		if err != nil {
+			return nil, err
+		}
+		return []Statement{stmt}, nil
+	case '[':
+		var requests []json.RawMessage
+		if err := json.Unmarshal(trimmed, &requests); err != nil {
+			return nil, fmt.Errorf("invalid statements array, %w", err)
+		}
+		if params != "" {
+			return nil, fmt.Errorf("params metadata cannot be set with a statements array")
+		}
+		var stmts []Statement
+		for i, request := range requests {
+			stmt, err := parseStatementObject(request, "", dialect)
+			if err != nil {
+				return nil, fmt.Errorf("error on statement %d, %w", i, err)
+			}
+			stmts = append(stmts, stmt)
+		}
+		return stmts, nil
+	}
+	var stmts []Statement
+	for _, query := range strings.Split(string(data), ";") {
+		if strings.TrimSpace(query) != "" {
+			stmts = append(stmts, Statement{Query: query})
+		}
+	}
+	if params == "" {
+		return stmts, nil
+	}
+	if len(stmts) != 1 {
+		return nil, fmt.Errorf("params metadata requires a single statement, found %d statements", len(stmts))
+	}
+	stmt, err := bindParams(stmts[0].Query, []byte(params), dialect)
+	if err != nil {
+		return nil, err
+	}
+	return []Statement{stmt}, nil
+}
+
+// ParseStatement parses the single statement of a query request, in the same formats as ParseStatements
+func ParseStatement(data []byte, params string, dialect *Dialect) (Statement, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseStatementObject(trimmed, params, dialect)
+	}
+	if params == "" {
+		return Statement{Query: string(data)}, nil
+	}
+	return bindParams(string(data), []byte(params), dialect)
+}
+
+func parseStatementObject(data []byte, params string, dialect *Dialect) (Statement, error) {
+	request := &statementRequest{}
+	if err := json.Unmarshal(data, request); err != nil {
+		return Statement{}, fmt.Errorf("invalid statement object, %w", err)
+	}
+	if strings.TrimSpace(request.Statement) == "" {
+		return Statement{}, fmt.Errorf("no statement found")
+	}
+	if params != "" {
+		if len(request.Params) > 0 {
+			return Statement{}, fmt.Errorf("params metadata cannot be set with statement params")
+		}
+		request.Params = json.RawMessage(params)
+	}
+	return bindParams(request.Statement, request.Params, dialect)
+}
+
+// bindParams binds a json array of positional parameters, or a json object of named parameters which are
+// referenced as :name in the query and replaced by the driver positional placeholders
+func bindParams(query string, params []byte, dialect *Dialect) (Statement, error) {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 || bytes.Equal(params, []byte("null")) {
+		return Statement{Query: query}, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return Statement{}, fmt.Errorf("invalid params, %w", err)
+	}
+	switch values := value.(type) {
+	case []interface{}:
+		args := make([]interface{}, 0, len(values))
+		for i, v := range values {
+			arg, err := convertParam(v)
+			if err != nil {
+				return Statement{}, fmt.Errorf("invalid param %d, %w", i+1, err)
+			}
+			args = append(args, arg)
+		}
+		return Statement{Query: query, Args: args}, nil
+	case map[string]interface{}:
+		named := map[string]interface{}{}
+		for name, v := range values {
+			arg, err := convertParam(v)
+			if err != nil {
+				return Statement{}, fmt.Errorf("invalid param %s, %w", name, err)
+			}
+			named[name] = arg
+		}
+		return bindNamedParams(query, named, dialect)
+	default:
+		return Statement{}, fmt.Errorf("invalid params, params must be a json array or object")
+	}
+}
+
+// convertParam converts a json param value to a driver value, integers are bound as int64 and other numbers as float64,
+// nested arrays and objects are bound as json strings, and typed params {"type":"...","value":...} as the declared type
+func convertParam(v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case nil, bool, string:
+		return value, nil
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i, nil
+		}
+		return value.Float64()
+	case map[string]interface{}:
+		if typ, ok := value["type"].(string); ok && len(value) == 2 {
+			if typed, ok := value["value"]; ok {
+				return convertTypedParam(typ, typed)
+			}
+		}
+		return marshalParam(value)
+	default:
+		return marshalParam(value)
+	}
+}
+
+func convertTypedParam(typ string, value interface{}) (interface{}, error) {
+	if value == nil || typ == "null" {
+		return nil, nil
+	}
+	str := fmt.Sprintf("%v", value)
+	switch typ {
+	case "string":
+		return str, nil
+	case "int":
+		return strconv.ParseInt(str, 10, 64)
+	case "float":
+		return strconv.ParseFloat(str, 64)
+	case "bool":
+		return strconv.ParseBool(str)
+	case "bytes":
+		return b64.StdEncoding.DecodeString(str)
+	case "time":
+		return time.Parse(time.RFC3339Nano, str)
+	case "json":
+		return marshalParam(value)
+	default:
+		return nil, fmt.Errorf("invalid param type %s", typ)
+	}
+}
+
+func marshalParam(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// bindNamedParams replaces :name references, outside of quoted strings, identifiers and comments, with positional placeholders
+func bindNamedParams(query string, named map[string]interface{}, dialect *Dialect) (Statement, error) {
+	var sb strings.Builder
+	var args []interface{}
+	positions := map[string]int{}
+	for i := 0; i < len(query); {
+		ch := query[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := skipQuoted(query, i, ch, dialect.backslashEscapes && ch != '`')
+			sb.WriteString(query[i:end])
+			i = end
+		case ch == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			sb.WriteString(query[i : i+end])
+			i += end
+		case ch == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i
+			} else {
+				end += 4
+			}
+			sb.WriteString(query[i : i+end])
+			i += end
+		case ch == '$' && dialect.dollarQuotes && dollarTag(query[i:]) != "":
+			tag := dollarTag(query[i:])
+			end := strings.Index(query[i+len(tag):], tag)
+			if end < 0 {
+				end = len(query) - i
+			} else {
+				end += 2 * len(tag)
+			}
+			sb.WriteString(query[i : i+end])
+			i += end
+		case ch == ':' && i+1 < len(query) && query[i+1] == ':':
+			sb.WriteString("::")
+			i += 2
+		case ch == ':' && i+1 < len(query) && isNameStart(query[i+1]) && (i == 0 || !isNameChar(query[i-1])):
+			end := i + 1
+			for end < len(query) && isNameChar(query[end]) {
+				end++
+			}
+			name := query[i+1 : end]
+			arg, ok := named[name]
+			if !ok {
+				return Statement{}, fmt.Errorf("missing param %s", name)
+			}
+			position, ok := positions[name]
+			if !ok || !dialect.numbered {
+				args = append(args, arg)
+				position = len(args)
+				positions[name] = position
+			}
+			sb.WriteString(dialect.placeholder(position))
+			i = end
+		default:
+			sb.WriteByte(ch)
+			i++
+		}
+	}
+	return Statement{Query: sb.String(), Args: args}, nil
+}
+
+// skipQuoted returns the index after the quoted string starting at start, a doubled quote is an escaped quote
+func skipQuoted(query string, start int, quote byte, backslashEscapes bool) int {
+	for i := start + 1; i < len(query); i++ {
+		if query[i] == '\\' && backslashEscapes {
+			i++
+			continue
+		}
+		if query[i] == quote {
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// dollarTag returns the $$ or $tag$ opening a dollar quoted string, or an empty string
+func dollarTag(s string) string {
+	if len(s) < 2 || s[0] != '$' {
+		return ""
+	}
+	if s[1] == '$' {
+		return "$$"
+	}
+	if !isNameStart(s[1]) {
+		return ""
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] == '$' {
+			return s[:i+1]
+		}
+		if !isNameChar(s[i]) {
+			return ""
+		}
+	}
+	return ""
+}
+
+func isNameStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isNameChar(ch byte) bool {
+	return isNameStart(ch) || (ch >= '0' && ch <= '9')
+}
@@ -0,0 +1,221 @@
+package sqlcore
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		params  string
+		dialect *Dialect
+		want    []Statement
+		wantErr bool
+	}{
+		{
+			name:    "empty",
+			data:    "",
+			dialect: Postgres,
+			want:    nil,
+		},
+		{
+			name:    "raw statements",
+			data:    "insert into t values (1);\n insert into t values (2);\n",
+			dialect: Postgres,
+			want: []Statement{
+				{Query: "insert into t values (1)"},
+				{Query: "\n insert into t values (2)"},
+			},
+		},
+		{
+			name:    "raw statement with positional params metadata",
+			data:    "insert into t values ($1,$2)",
+			params:  `[1,"a"]`,
+			dialect: Postgres,
+			want: []Statement{
+				{Query: "insert into t values ($1,$2)", Args: []interface{}{int64(1), "a"}},
+			},
+		},
+		{
+			name:    "raw statement with named params metadata",
+			data:    "insert into t values (:id,:name,:id)",
+			params:  `{"id":1,"name":"a"}`,
+			dialect: Postgres,
+			want: []Statement{
+				{Query: "insert into t values ($1,$2,$1)", Args: []interface{}{int64(1), "a"}},
+			},
+		},
+		{
+			name:    "raw statements with params metadata",
+			data:    "insert into t values ($1);insert into t values ($1)",
+			params:  `[1]`,
+			dialect: Postgres,
+			wantErr: true,
+		},
+		{
+			name:    "statement object",
+			data:    `{"statement":"update t set name=:name where id=:id","params":{"id":1.5,"name":null}}`,
+			dialect: MySQL,
+			want: []Statement{
+				{Query: "update t set name=? where id=?", Args: []interface{}{nil, 1.5}},
+			},
+		},
+		{
+			name:    "statements array",
+			data:    `[{"statement":"delete from t where id=@p1","params":[1]},{"statement":"delete from t"}]`,
+			dialect: SQLServer,
+			want: []Statement{
+				{Query: "delete from t where id=@p1", Args: []interface{}{int64(1)}},
+				{Query: "delete from t"},
+			},
+		},
+		{
+			name:    "statements array with params metadata",
+			data:    `[{"statement":"delete from t"}]`,
+			params:  `[1]`,
+			dialect: Postgres,
+			wantErr: true,
+		},
+		{
+			name:    "statement object without statement",
+			data:    `{"params":[1]}`,
+			dialect: Postgres,
+			wantErr: true,
+		},
+		{
+			name:    "missing named param",
+			data:    `{"statement":"select :id","params":{"name":"a"}}`,
+			dialect: Postgres,
+			wantErr: true,
+		},
+		{
+			name:    "invalid params",
+			data:    `{"statement":"select $1","params":"a"}`,
+			dialect: Postgres,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStatements([]byte(tt.data), tt.params, tt.dialect)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseStatement(t *testing.T) {
+	got, err := ParseStatement([]byte("select * from t where id=:id"), `{"id":"1"}`, MSSQL)
+	require.NoError(t, err)
+	require.Equal(t, Statement{Query: "select * from t where id=?", Args: []interface{}{"1"}}, got)
+	got, err = ParseStatement([]byte("select * from t"), "", MSSQL)
+	require.NoError(t, err)
+	require.Equal(t, Statement{Query: "select * from t"}, got)
+}
+
+func TestBindNamedParams(t *testing.T) {
+	named := map[string]interface{}{"id": int64(1)}
+	tests := []struct {
+		name    string
+		query   string
+		dialect *Dialect
+		want    string
+	}{
+		{
+			name:    "quoted strings and identifiers",
+			query:   `select ':id', "a:id", :id from t`,
+			dialect: Postgres,
+			want:    `select ':id', "a:id", $1 from t`,
+		},
+		{
+			name:    "escaped quotes",
+			query:   `select 'it''s :id', :id`,
+			dialect: Postgres,
+			want:    `select 'it''s :id', $1`,
+		},
+		{
+			name:    "backslash escapes",
+			query:   `select 'it\'s :id', :id`,
+			dialect: MySQL,
+			want:    `select 'it\'s :id', ?`,
+		},
+		{
+			name:    "postgres casts",
+			query:   `select :id::text, now()::date`,
+			dialect: Postgres,
+			want:    `select $1::text, now()::date`,
+		},
+		{
+			name:    "comments",
+			query:   "select :id -- :id\n/* :id */ from t",
+			dialect: SQLServer,
+			want:    "select @p1 -- :id\n/* :id */ from t",
+		},
+		{
+			name:    "mysql assignment",
+			query:   "set @a := :id",
+			dialect: MySQL,
+			want:    "set @a := ?",
+		},
+		{
+			name:    "dollar quoted strings",
+			query:   "select $$ :id $$, $fn$ select ':id' :id $fn$, :id, $1",
+			dialect: Postgres,
+			want:    "select $$ :id $$, $fn$ select ':id' :id $fn$, $1, $1",
+		},
+		{
+			name:    "dollar signs without dollar quotes",
+			query:   "select '$$', :id",
+			dialect: MySQL,
+			want:    "select '$$', ?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bindNamedParams(tt.query, named, tt.dialect)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.Query)
+			require.Equal(t, []interface{}{int64(1)}, got.Args)
+		})
+	}
+}
+
+func TestConvertParam(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "int", params: `[10]`, want: int64(10)},
+		{name: "float", params: `[1.25]`, want: 1.25},
+		{name: "bool", params: `[true]`, want: true},
+		{name: "nested object", params: `[{"a":1}]`, want: `{"a":1}`},
+		{name: "nested array", params: `[[1,2]]`, want: `[1,2]`},
+		{name: "typed bytes", params: `[{"type":"bytes","value":"AQI="}]`, want: []byte{1, 2}},
+		{name: "typed time", params: `[{"type":"time","value":"2021-01-01T00:00:00Z"}]`, want: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "typed int", params: `[{"type":"int","value":"12"}]`, want: int64(12)},
+		{name: "typed json", params: `[{"type":"json","value":{"a":[1]}}]`, want: `{"a":[1]}`},
+		{name: "typed null", params: `[{"type":"null","value":1}]`, want: nil},
+		{name: "bad typed int", params: `[{"type":"int","value":"a"}]`, wantErr: true},
+		{name: "bad type", params: `[{"type":"money","value":"1"}]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bindParams("select $1", []byte(tt.params), Postgres)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []interface{}{tt.want}, got.Args)
+		})
+	}
+}
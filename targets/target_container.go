@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/kubemq-hub/builder/connector/common"
 	"github.com/kubemq-hub/kubemq-targets/pkg/logger"
+	"github.com/kubemq-hub/kubemq-targets/pkg/secrets"
 	"github.com/kubemq-hub/kubemq-targets/targets/aws/amazonmq"
 	"github.com/kubemq-hub/kubemq-targets/targets/aws/athena"
 	"github.com/kubemq-hub/kubemq-targets/targets/aws/cloudwatch/events"
@@ -88,7 +89,21 @@ type Target interface {
 	Connector() *common.Connector
 }
 
+// Init resolves secret references of the target properties and initializes the target connector
 func Init(ctx context.Context, cfg config.Spec, log *logger.Logger) (Target, error) {
+	properties, err := secrets.Resolve(cfg.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving secrets, %w", err)
+	}
+	cfg.Properties = properties
+	connector, err := initTarget(ctx, cfg, log)
+	if err != nil {
+		return nil, secrets.RedactError(err, cfg.Kind, properties)
+	}
+	return connector, nil
+}
+
+func initTarget(ctx context.Context, cfg config.Spec, log *logger.Logger) (Target, error) {
 
 	switch cfg.Kind {
 	case "storage.filesystem":
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrorClass classifies target errors, so middlewares such as retry can handle each class differently
+type ErrorClass string
+
+const (
+	ErrorClassUnknown        ErrorClass = "unknown"
+	ErrorClassInvalidRequest ErrorClass = "invalid_request"
+	ErrorClassTransient      ErrorClass = "transient"
+	ErrorClassThrottled      ErrorClass = "throttled"
+	ErrorClassNotFound       ErrorClass = "not_found"
+	ErrorClassConflict       ErrorClass = "conflict"
+)
+
+// ClassifiedError is an error returned by a target with its error class and an optional retry after hint
+type ClassifiedError struct {
+	Class      ErrorClass
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+func newClassifiedError(class ErrorClass, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassifiedError{
+		Class: class,
+		Err:   err,
+	}
+}
+
+// NewInvalidRequestError returns an error of a request which cannot succeed as is, such as invalid metadata
+func NewInvalidRequestError(err error) error {
+	return newClassifiedError(ErrorClassInvalidRequest, err)
+}
+
+// NewTransientError returns an error which may succeed when retried, such as a connection error
+func NewTransientError(err error) error {
+	return newClassifiedError(ErrorClassTransient, err)
+}
+
+// NewThrottledError returns an error of a request rejected by the target rate limits, retryAfter is optional
+func NewThrottledError(err error, retryAfter time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassifiedError{
+		Class:      ErrorClassThrottled,
+		RetryAfter: retryAfter,
+		Err:        err,
+	}
+}
+
+// NewNotFoundError returns an error of a request for a missing resource
+func NewNotFoundError(err error) error {
+	return newClassifiedError(ErrorClassNotFound, err)
+}
+
+// NewConflictError returns an error of a request conflicting with the current state of a resource
+func NewConflictError(err error) error {
+	return newClassifiedError(ErrorClassConflict, err)
+}
+
+// ErrorClassOf returns the class of err, or ErrorClassUnknown when err is not classified
+func ErrorClassOf(err error) ErrorClass {
+	var classified *ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.Class
+	}
+	return ErrorClassUnknown
+}
+
+// RetryAfterOf returns the retry after hint of err, or 0 when there is no hint
+func RetryAfterOf(err error) time.Duration {
+	var classified *ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.RetryAfter
+	}
+	return 0
+}
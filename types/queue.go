@@ -0,0 +1,46 @@
+package types
+
+import (
+	"github.com/kubemq-io/kubemq-go/queues_stream"
+	"strconv"
+	"time"
+)
+
+// queue message attributes set as request metadata by the queue source
+const (
+	QueueMessageIdKey           = "kubemq_message_id"
+	QueueMessageTagsKey         = "kubemq_tags"
+	QueueMessageSequenceKey     = "kubemq_sequence"
+	QueueMessageReceiveCountKey = "kubemq_receive_count"
+	QueueMessageTimestampKey    = "kubemq_timestamp"
+)
+
+// queue message policy keys of response metadata, applied to response queue messages
+const (
+	QueueMessageDelaySecondsKey      = "kubemq_delay_seconds"
+	QueueMessageExpirationSecondsKey = "kubemq_expiration_seconds"
+	QueueMessageMaxReceiveCountKey   = "kubemq_max_receive_count"
+	QueueMessageMaxReceiveQueueKey   = "kubemq_max_receive_queue"
+)
+
+// SetQueueMessageAttributes sets the queue message id, tags and attributes as request metadata
+func (r *Request) SetQueueMessageAttributes(message *queues_stream.QueueMessage) *Request {
+	if r.Metadata == nil {
+		r.Metadata = NewMetadata()
+	}
+	r.Metadata.Set(QueueMessageIdKey, message.MessageID)
+	if len(message.Tags) > 0 {
+		tags, err := json.MarshalToString(message.Tags)
+		if err == nil {
+			r.Metadata.Set(QueueMessageTagsKey, tags)
+		}
+	}
+	if message.Attributes != nil {
+		r.Metadata.Set(QueueMessageSequenceKey, strconv.FormatUint(message.Attributes.Sequence, 10))
+		r.Metadata.Set(QueueMessageReceiveCountKey, strconv.Itoa(int(message.Attributes.ReceiveCount)))
+		if message.Attributes.Timestamp > 0 {
+			r.Metadata.Set(QueueMessageTimestampKey, time.Unix(0, message.Attributes.Timestamp).UTC().Format(time.RFC3339Nano))
+		}
+	}
+	return r
+}
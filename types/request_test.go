@@ -1,9 +1,12 @@
 package types
 
 import (
+	"github.com/kubemq-io/kubemq-go/queues_stream"
+	pb "github.com/kubemq-io/protobuf/go"
 	"github.com/stretchr/testify/require"
 	"io/ioutil"
 	"testing"
+	"time"
 )
 
 func loadFile(filename string) []byte {
@@ -98,3 +101,70 @@ func TestParseRequest(t *testing.T) {
 
 	}
 }
+
+func TestRequest_SetQueueMessageAttributes(t *testing.T) {
+	message := queues_stream.NewQueueMessage().
+		SetId("message-id").
+		SetTags(map[string]string{"key": "value"})
+	message.Attributes = &pb.QueueMessageAttributes{
+		Timestamp:    time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano(),
+		Sequence:     10,
+		ReceiveCount: 2,
+	}
+	req := NewRequest().SetQueueMessageAttributes(message)
+	require.Equal(t, "message-id", req.Metadata.Get(QueueMessageIdKey))
+	require.Equal(t, `{"key":"value"}`, req.Metadata.Get(QueueMessageTagsKey))
+	require.Equal(t, "10", req.Metadata.Get(QueueMessageSequenceKey))
+	require.Equal(t, "2", req.Metadata.Get(QueueMessageReceiveCountKey))
+	require.Equal(t, "2021-01-01T00:00:00Z", req.Metadata.Get(QueueMessageTimestampKey))
+}
+
+func TestResponse_ToQueueStreamMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata Metadata
+		wantTags map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "no policy",
+			metadata: NewMetadata(),
+			wantTags: map[string]string{},
+		},
+		{
+			name: "tags and policy",
+			metadata: NewMetadata().
+				Set(QueueMessageTagsKey, `{"key":"value"}`).
+				Set(QueueMessageDelaySecondsKey, "10").
+				Set(QueueMessageExpirationSecondsKey, "20").
+				Set(QueueMessageMaxReceiveCountKey, "3").
+				Set(QueueMessageMaxReceiveQueueKey, "dead-letter"),
+			wantTags: map[string]string{"key": "value"},
+		},
+		{
+			name:     "invalid tags",
+			metadata: NewMetadata().Set(QueueMessageTagsKey, "bad-tags"),
+			wantErr:  true,
+		},
+		{
+			name:     "invalid delay",
+			metadata: NewMetadata().Set(QueueMessageDelaySecondsKey, "-1"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := NewResponse().SetMetadata(tt.metadata).ToQueueStreamMessage()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantTags, msg.Tags)
+			require.EqualValues(t, tt.metadata.ParseInt(QueueMessageDelaySecondsKey, 0), msg.Policy.DelaySeconds)
+			require.EqualValues(t, tt.metadata.ParseInt(QueueMessageExpirationSecondsKey, 0), msg.Policy.ExpirationSeconds)
+			require.EqualValues(t, tt.metadata.ParseInt(QueueMessageMaxReceiveCountKey, 0), msg.Policy.MaxReceiveCount)
+			require.Equal(t, tt.metadata.Get(QueueMessageMaxReceiveQueueKey), msg.Policy.MaxReceiveQueue)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/kubemq-io/kubemq-go"
 	"github.com/kubemq-io/kubemq-go/queues_stream"
+	"math"
 )
 
 type Response struct {
@@ -68,9 +69,43 @@ func (r *Response) ToQueueMessage() *kubemq.QueueMessage {
 	return kubemq.NewQueueMessage().
 		SetBody(r.MarshalBinary())
 }
-func (r *Response) ToQueueStreamMessage() *queues_stream.QueueMessage {
-	return queues_stream.NewQueueMessage().
+
+// ToQueueStreamMessage returns a queue message of the response, with the tags and policy set by the response metadata
+func (r *Response) ToQueueStreamMessage() (*queues_stream.QueueMessage, error) {
+	msg := queues_stream.NewQueueMessage().
 		SetBody(r.MarshalBinary())
+	if tags := r.Metadata.Get(QueueMessageTagsKey); tags != "" {
+		tagsMap := map[string]string{}
+		if err := json.UnmarshalFromString(tags, &tagsMap); err != nil {
+			return nil, fmt.Errorf("invalid %s value, %w", QueueMessageTagsKey, err)
+		}
+		msg.SetTags(tagsMap)
+	}
+	delay, err := r.Metadata.ParseIntWithRange(QueueMessageDelaySecondsKey, 0, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value, %w", QueueMessageDelaySecondsKey, err)
+	}
+	if delay > 0 {
+		msg.SetPolicyDelaySeconds(delay)
+	}
+	expiration, err := r.Metadata.ParseIntWithRange(QueueMessageExpirationSecondsKey, 0, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value, %w", QueueMessageExpirationSecondsKey, err)
+	}
+	if expiration > 0 {
+		msg.SetPolicyExpirationSeconds(expiration)
+	}
+	maxReceiveCount, err := r.Metadata.ParseIntWithRange(QueueMessageMaxReceiveCountKey, 0, 0, math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value, %w", QueueMessageMaxReceiveCountKey, err)
+	}
+	if maxReceiveCount > 0 {
+		msg.SetPolicyMaxReceiveCount(maxReceiveCount)
+	}
+	if maxReceiveQueue := r.Metadata.Get(QueueMessageMaxReceiveQueueKey); maxReceiveQueue != "" {
+		msg.SetPolicyMaxReceiveQueue(maxReceiveQueue)
+	}
+	return msg, nil
 }
 func (r *Response) ToResponse() *kubemq.Response {
 	return kubemq.NewResponse().